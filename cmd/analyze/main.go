@@ -7,9 +7,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 
+	"github.com/chrisfenner/slh-dsa-rls/pkg/perf"
 	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
 	"github.com/jedib0t/go-pretty/table"
 	"github.com/jedib0t/go-pretty/text"
@@ -17,7 +17,21 @@ import (
 )
 
 var (
-	tableFormat = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	tableFormat   = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	precise       = flag.Bool("precise", false, "also print the security level at --sig_count signatures, computed both quickly and with arbitrary precision")
+	sigCount      = flag.Float64("sig_count", 20, "log_2 of the number of signatures to compute the security level of the whole scheme (and with --precise, of FORS) at")
+	numKeys       = flag.Int("num_keys", 1, "if more than 1, also print the security level at --sig_count signatures per key for a fleet of this many keys, and the total signatures the fleet can make")
+	securityFloor = flag.String("security_floor", "", "CSV file of (log_2 signatures, security level) breakpoints of a piecewise-linear curve; if set, also print the minimum margin to it")
+	quantum       = flag.Bool("quantum", false, "also print the security levels at --sig_count signatures against a quantum attacker, and the NIST category")
+	hashFamily    = flag.String("hash_family", slhdsa.DefaultHashFamily.Name(), "instantiation of the hash functions that signing and verification work is counted for, one of ('"+strings.Join(slhdsa.HashFamilyNames(), "', '")+"')")
+	messageBytes  = flag.Int("message_bytes", slhdsa.DefaultMessageBytes, "length in bytes of the messages that are signed, which signing and verification process (a hash per 64 bytes with --hash_family 'abstract')")
+	signCores     = flag.Int("sign_cores", 1, "number of cores (parallel hash units) that sign, so that the signing work and seconds are the time to sign with all of them")
+	preHash       = flag.Bool("pre_hash", false, "pre-hash messages (HashSLH-DSA), so that signing and verification process them only once")
+	calibration   = flag.String("calibration", "", "profile saved by the calibrate command; if set, also print the estimated seconds to sign and verify on the calibrated machine")
+	profiles      = flag.String("profiles", "", "JSON file of additional performance profiles (a profile or a list of them) for --signer_profile and --verifier_profile")
+	signerProf    = flag.String("signer_profile", "", "performance profile of the signing device, one of ('"+strings.Join(perf.BuiltinProfiles().Names(), "', '")+"') or one from --profiles; if set, also print the estimated seconds to sign on it")
+	verifierProf  = flag.String("verifier_profile", "", "performance profile of the verifying device, one of ('"+strings.Join(perf.BuiltinProfiles().Names(), "', '")+"') or one from --profiles; if set, also print the estimated seconds to verify on it")
+	securityModel = flag.String("security_model", slhdsa.DefaultSecurityModel.Name(), "analysis used to compute the security level, one of ('"+strings.Join(slhdsa.SecurityModelNames(), "', '")+"')")
 )
 
 func main() {
@@ -40,6 +54,40 @@ type namedParms struct {
 }
 
 func mainErr() error {
+	model, err := slhdsa.SecurityModelByName(*securityModel)
+	if err != nil {
+		return err
+	}
+	family, err := slhdsa.HashFamilyByName(*hashFamily)
+	if err != nil {
+		return err
+	}
+	if *signCores < 1 {
+		return fmt.Errorf("--sign_cores must be at least 1")
+	}
+	if *precise && model.Name() != (slhdsa.Fluhrer{}).Name() {
+		return fmt.Errorf("--precise is only supported with the %q security model", (slhdsa.Fluhrer{}).Name())
+	}
+
+	var floor slhdsa.SecurityFloor
+	if *securityFloor != "" {
+		f, err := os.Open(*securityFloor)
+		if err != nil {
+			return err
+		}
+		floor, err = slhdsa.ParseSecurityFloor(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	// --calibration times both signing and verification, unless overridden by a named profile
+	signer, verifier, err := perf.BuiltinProfiles().Devices(*calibration, *profiles, *signerProf, *verifierProf)
+	if err != nil {
+		return err
+	}
+
 	var parms []namedParms
 
 	// Print a prompt if the program is being run from an interactive terminal
@@ -54,11 +102,16 @@ func mainErr() error {
 		if line == "" {
 			break
 		}
-		id, parm, err := getParameterSetFromLine(line)
+		id, rest, _ := strings.Cut(line, " ")
+		parm, err := slhdsa.ParseParameterSet(rest)
 		if err != nil {
-			return err
+			return fmt.Errorf("parameter set %q: %v", id, err)
 		}
-		parms = append(parms, namedParms{id: id, ParameterSet: *parm})
+		parm.SecurityModel = model
+		parm.HashFamily = family
+		parm.MessageBytes = *messageBytes
+		parm.PreHash = *preHash
+		parms = append(parms, namedParms{id: id, ParameterSet: parm})
 	}
 
 	t := table.NewWriter()
@@ -75,7 +128,7 @@ func mainErr() error {
 		os.Exit(1)
 	}
 
-	t.AppendHeader(table.Row{
+	header := table.Row{
 		"id",
 		"s",
 		"h",
@@ -90,10 +143,51 @@ func mainErr() error {
 		"verify work",
 		"sigs",
 		"sigs at reduced",
-	})
+		fmt.Sprintf("scheme security at 2^%v", *sigCount),
+		"bottleneck",
+	}
+	if *numKeys > 1 {
+		header = append(header,
+			fmt.Sprintf("fleet security at 2^%v", *sigCount),
+			"fleet sigs",
+		)
+	}
+	if len(floor) > 0 {
+		header = append(header, "floor margin")
+	}
+	if *quantum {
+		header = append(header,
+			fmt.Sprintf("quantum security at 2^%v", *sigCount),
+			fmt.Sprintf("quantum scheme security at 2^%v", *sigCount),
+			"category",
+		)
+	}
+	if signer != nil {
+		header = append(header,
+			"sign seconds",
+			"cached sign seconds",
+		)
+	}
+	if verifier != nil {
+		header = append(header, "verify seconds")
+	}
+	if *precise {
+		header = append(header,
+			fmt.Sprintf("security at 2^%v", *sigCount),
+			fmt.Sprintf("precise security at 2^%v", *sigCount),
+		)
+	}
+	t.AppendHeader(header)
 
 	for _, parm := range parms {
-		t.AppendRow(table.Row{
+		scheme := parm.SchemeSecurityLevel(*sigCount)
+		var bottlenecks []string
+		for _, component := range scheme.Bottlenecks() {
+			bottlenecks = append(bottlenecks, component.Name)
+		}
+		// With more than one core, the time to sign on them
+		signWork := parm.ParallelSignatureCost(*signCores)
+		row := table.Row{
 			parm.id,                  // "id",
 			parm.TargetSecurityLevel, // "s",
 			parm.HypertreeHeight(),   // "h",
@@ -104,63 +198,78 @@ func mainErr() error {
 			parm.LgW,                 // "lg_w",
 			parm.M(),                 // "m",
 			parm.SignatureSize(),     // "sig bytes",
-			parm.SignatureHashes(),   // "sign work",
-			parm.VerifyHashes(),      // "verify work",
+			signWork,                 // "sign work",
+			parm.VerifyCost(),        // "verify work",
 			parm.SignaturesAtLevel(parm.TargetSecurityLevel),  // "sigs",
 			parm.SignaturesAtLevel(parm.OveruseSecurityLevel), // "sigs at {fallbackSecurityLevel}",
-		})
+			fmt.Sprintf("%.2f", scheme.SecurityLevel),         // "scheme security at 2^{sigCount}",
+			strings.Join(bottlenecks, "/"),                    // "bottleneck",
+		}
+		if *numKeys > 1 {
+			row = append(row,
+				fmt.Sprintf("%.2f", parm.FleetSecurityLevel(*sigCount, *numKeys)), // "fleet security at 2^{sigCount}",
+				parm.FleetSignaturesAtLevel(parm.TargetSecurityLevel, *numKeys),   // "fleet sigs",
+			)
+		}
+		if len(floor) > 0 {
+			row = append(row, fmt.Sprintf("%.2f", parm.FleetFloorMargin(floor, *numKeys))) // "floor margin",
+		}
+		if *quantum {
+			security := parm.QuantumSecurityLevel(*sigCount)
+			row = append(row,
+				fmt.Sprintf("%.2f", security),                       // "quantum security at 2^{sigCount}",
+				fmt.Sprintf("%.2f", scheme.Quantum().SecurityLevel), // "quantum scheme security at 2^{sigCount}",
+				slhdsa.NISTCategoryOf(security),                     // "category",
+			)
+		}
+		if signer != nil {
+			sign, err := signer.ParallelSignatureSeconds(parm.ParameterSet, *signCores)
+			if err != nil {
+				return fmt.Errorf("profile %q: %v", signer.Name, err)
+			}
+			cachedSign, err := signer.ParallelCachedSignatureSeconds(parm.ParameterSet, *signCores)
+			if err != nil {
+				return fmt.Errorf("profile %q: %v", signer.Name, err)
+			}
+			row = append(row,
+				fmt.Sprintf("%.3g", sign),       // "sign seconds",
+				fmt.Sprintf("%.3g", cachedSign), // "cached sign seconds",
+			)
+		}
+		if verifier != nil {
+			verify, err := verifier.VerifySeconds(parm.ParameterSet)
+			if err != nil {
+				return fmt.Errorf("profile %q: %v", verifier.Name, err)
+			}
+			row = append(row, fmt.Sprintf("%.3g", verify)) // "verify seconds",
+		}
+		if *precise {
+			interval := parm.PreciseSecurityLevel(*sigCount)
+			row = append(row,
+				fmt.Sprintf("%.12f", parm.ComputeSecurityLevel(*sigCount)),    // "security at 2^{sigCount}",
+				fmt.Sprintf("%.12f to %.12f", interval.Lower, interval.Upper), // "precise security at 2^{sigCount}",
+			)
+		}
+		t.AppendRow(row)
 	}
 
 	t.SetStyle(table.StyleColoredDark)
 	t.Style().Title.Align = text.AlignCenter
 	title := "Selected Parameter Sets"
-	t.SetTitle(title)
-	fmt.Println(render())
-	return nil
-}
-
-func getParameterSetFromLine(line string) (string, *slhdsa.ParameterSet, error) {
-	split := strings.Split(line, " ")
-	if len(split) != 8 {
-		return "", nil, fmt.Errorf("expected format: (id, overuse, n, d, h', a, k, lg_w); got %d fields", len(split))
-	}
-	id := split[0]
-	overuse, err := strconv.ParseInt(split[1], 10, 32)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not parse overuse from %q: %v", split[1], err)
+	if family != slhdsa.DefaultHashFamily {
+		title += fmt.Sprintf(" (work in %s)", family.Unit())
 	}
-	n, err := strconv.ParseInt(split[2], 10, 32)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not parse n from %q: %v", split[2], err)
-	}
-	d, err := strconv.ParseInt(split[3], 10, 32)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not parse d from %q: %v", split[3], err)
-	}
-	hp, err := strconv.ParseInt(split[4], 10, 32)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not parse h' from %q: %v", split[4], err)
-	}
-	a, err := strconv.ParseInt(split[5], 10, 32)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not parse a from %q: %v", split[5], err)
+	if *messageBytes != slhdsa.DefaultMessageBytes || *preHash {
+		title += fmt.Sprintf(" (%d-byte messages", *messageBytes)
+		if *preHash {
+			title += ", pre-hashed"
+		}
+		title += ")"
 	}
-	k, err := strconv.ParseInt(split[6], 10, 32)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not parse k from %q: %v", split[6], err)
+	if *signCores > 1 {
+		title += fmt.Sprintf(" (%d signing cores)", *signCores)
 	}
-	lgw, err := strconv.ParseInt(split[7], 10, 32)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not parse lg_w from %q: %v", split[7], err)
-	}
-
-	return id, &slhdsa.ParameterSet{
-		TargetSecurityLevel:  int(n) * 8,
-		OveruseSecurityLevel: int(overuse),
-		D:                    int(d),
-		HPrime:               int(hp),
-		T:                    int(a),
-		K:                    int(k),
-		LgW:                  int(lgw),
-	}, nil
+	t.SetTitle(title)
+	fmt.Println(render())
+	return nil
 }
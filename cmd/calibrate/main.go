@@ -0,0 +1,77 @@
+// Package main contains the entry logic for calibrate
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/perf"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+)
+
+var (
+	tableFormat = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	output      = flag.String("output", "calibration.json", "file to save the profile to, for the --calibration flag of slushfind and analyze")
+	duration    = flag.Duration("duration", time.Second, "how long to measure each hash primitive for")
+)
+
+func main() {
+	flag.Parse()
+	extraArgs := flag.Args()
+	if len(extraArgs) != 0 {
+		fmt.Fprintf(os.Stderr, "unrecognized arguments: %v", strings.Join(extraArgs, ", "))
+		os.Exit(1)
+	}
+
+	if err := mainErr(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func mainErr() error {
+	t := table.NewWriter()
+	var render func() string
+	switch strings.ToLower(*tableFormat) {
+	case "console":
+		render = t.Render
+	case "markdown":
+		render = t.RenderMarkdown
+	case "csv":
+		render = t.RenderCSV
+	default:
+		return fmt.Errorf("unrecognized table format: %v", *tableFormat)
+	}
+
+	prof := perf.Calibrate(*duration)
+	if err := prof.Save(*output); err != nil {
+		return err
+	}
+
+	t.AppendHeader(table.Row{
+		"primitive",
+		"ns per call",
+		"calls per second",
+	})
+	for _, primitive := range slices.Sorted(maps.Keys(prof.Seconds)) {
+		seconds := prof.Seconds[primitive]
+		t.AppendRow(table.Row{
+			primitive,
+			fmt.Sprintf("%.1f", seconds*1e9),
+			fmt.Sprintf("%.3g", 1/seconds),
+		})
+	}
+
+	t.SetStyle(table.StyleColoredDark)
+	t.Style().Title.Align = text.AlignCenter
+	t.SetTitle(fmt.Sprintf("Hash Throughput on %v (saved to %v)", prof.Machine, *output))
+	fmt.Println(render())
+	return nil
+}
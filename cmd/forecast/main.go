@@ -0,0 +1,155 @@
+// Package main contains the entry logic for forecast
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/lifetime"
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+	"golang.org/x/term"
+)
+
+var (
+	tableFormat   = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	logFile       = flag.String("log_file", "", "log of the times of the key's past signatures, one per line as CSV (time in the first field) or JSON (time in the \"timestamp\" field); times are RFC 3339 or Unix seconds")
+	parameterSet  = flag.String("parameter_set", "", "name of a standard parameter set, one of ('"+strings.Join(slhdsa.ParameterSetNames(), "', '")+"'); if not set, the parameter set is read from the input")
+	rate          = flag.String("rate", "", "signing rate to project with, as COUNT/UNIT (UNIT one of s, m, h, d or y); if not set, the rate is fitted to the log")
+	securityModel = flag.String("security_model", slhdsa.DefaultSecurityModel.Name(), "analysis used to compute the security level, one of ('"+strings.Join(slhdsa.SecurityModelNames(), "', '")+"')")
+)
+
+func main() {
+	flag.Parse()
+	extraArgs := flag.Args()
+	if len(extraArgs) != 0 {
+		fmt.Fprintf(os.Stderr, "unrecognized arguments: %v", strings.Join(extraArgs, ", "))
+		os.Exit(1)
+	}
+
+	if err := mainErr(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func mainErr() error {
+	if *logFile == "" {
+		return fmt.Errorf("--log_file is required")
+	}
+	f, err := os.Open(*logFile)
+	if err != nil {
+		return err
+	}
+	times, err := lifetime.ReadSigningLog(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	var signingRate float64
+	if *rate != "" {
+		signingRate, err = lifetime.ParseRate(*rate)
+	} else {
+		signingRate, err = lifetime.FitRate(times)
+	}
+	if err != nil {
+		return err
+	}
+
+	var parms slhdsa.ParameterSet
+	name := *parameterSet
+	if name != "" {
+		parms, err = slhdsa.ParameterSetByName(name)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Print a prompt if the program is being run from an interactive terminal
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Printf("Enter the values (overuse, n, d, h', a, k, lg_w) for the parameter set\n")
+		}
+
+		// Read the parameter set from the input.
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		parms, err = slhdsa.ParseParameterSet(scanner.Text())
+		if err != nil {
+			return err
+		}
+		name = "Parameter Set"
+	}
+	parms.SecurityModel, err = slhdsa.SecurityModelByName(*securityModel)
+	if err != nil {
+		return err
+	}
+
+	t := table.NewWriter()
+	var render func() string
+	switch strings.ToLower(*tableFormat) {
+	case "console":
+		render = t.Render
+	case "markdown":
+		render = t.RenderMarkdown
+	case "csv":
+		render = t.RenderCSV
+	default:
+		return fmt.Errorf("unrecognized table format: %v", *tableFormat)
+	}
+
+	// Years are counted from the last signature, or from now if there are none
+	from, yearsHeader := time.Now(), "years from now"
+	if len(times) > 0 {
+		from, yearsHeader = times[len(times)-1], "years after last sig"
+	}
+	t.AppendHeader(table.Row{
+		"security level",
+		"sigs at level",
+		"budget used",
+		"date below level",
+		yearsHeader,
+	})
+	levels := []int{parms.TargetSecurityLevel, parms.OveruseSecurityLevel}
+	for _, forecast := range lifetime.Project(parms, times, signingRate, levels) {
+		date, years := "never", "never"
+		if !forecast.Never {
+			date = forecast.Date.Format(time.DateOnly)
+			// Computed from Unix times, since a time.Duration only spans about 292 years
+			years = fmt.Sprintf("%.2f", float64(forecast.Date.Unix()-from.Unix())/lifetime.SecondsPerYear)
+		}
+		t.AppendRow(table.Row{
+			forecast.SecurityLevel,
+			forecast.Signatures,
+			fmt.Sprintf("%.4g%%", 100*forecast.Used),
+			date,
+			years,
+		})
+	}
+
+	// The budget consumed so far (a key that has made no signatures is as secure as after its first)
+	sigs := fmt.Sprintf("sigs: %d", len(times))
+	var lgSigs float64
+	if len(times) > 0 {
+		lgSigs = math.Log2(float64(len(times)))
+		sigs += fmt.Sprintf(" (2^%.2f)", lgSigs)
+	}
+	t.AppendFooter(table.Row{
+		"",
+		sigs,
+		fmt.Sprintf("security level: %.2f", parms.SecurityLevel(lgSigs)),
+		fmt.Sprintf("rate: %.3g sigs/day", signingRate*24*60*60),
+	})
+
+	t.SetStyle(table.StyleColoredDark)
+	t.Style().Title.Align = text.AlignCenter
+	t.SetTitle(fmt.Sprintf("Signing Forecast for %v", name))
+	fmt.Println(render())
+	return nil
+}
@@ -0,0 +1,150 @@
+// Package main contains the entry logic for ledger
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/ledger"
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+	"golang.org/x/term"
+)
+
+var (
+	tableFormat   = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	ledgerFile    = flag.String("ledger_file", "ledger.json", "file to store the signature counts in")
+	key           = flag.String("key", "", "ID of the key in the ledger")
+	record        = flag.Bool("record", false, "record one more signature for --key before printing its state; the program fails if the signature is refused")
+	parameterSet  = flag.String("parameter_set", "", "name of a standard parameter set, one of ('"+strings.Join(slhdsa.ParameterSetNames(), "', '")+"'); if not set, the parameter set is read from the input")
+	belowTarget   = flag.String("below_target", ledger.DefaultPolicy.BelowTarget.String(), "action when a signature would push the key below its target security level, one of ('allow', 'warn', 'refuse')")
+	belowOveruse  = flag.String("below_overuse", ledger.DefaultPolicy.BelowOveruse.String(), "action when a signature would push the key below its overuse security level, one of ('allow', 'warn', 'refuse')")
+	securityModel = flag.String("security_model", slhdsa.DefaultSecurityModel.Name(), "analysis used to compute the security level, one of ('"+strings.Join(slhdsa.SecurityModelNames(), "', '")+"')")
+)
+
+func main() {
+	flag.Parse()
+	extraArgs := flag.Args()
+	if len(extraArgs) != 0 {
+		fmt.Fprintf(os.Stderr, "unrecognized arguments: %v", strings.Join(extraArgs, ", "))
+		os.Exit(1)
+	}
+
+	if err := mainErr(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func mainErr() error {
+	if *key == "" {
+		return fmt.Errorf("--key is required")
+	}
+	var policy ledger.Policy
+	var err error
+	if policy.BelowTarget, err = ledger.ParseAction(*belowTarget); err != nil {
+		return err
+	}
+	if policy.BelowOveruse, err = ledger.ParseAction(*belowOveruse); err != nil {
+		return err
+	}
+
+	var parms slhdsa.ParameterSet
+	if *parameterSet != "" {
+		parms, err = slhdsa.ParameterSetByName(*parameterSet)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Print a prompt if the program is being run from an interactive terminal
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Printf("Enter the values (overuse, n, d, h', a, k, lg_w) for the parameter set\n")
+		}
+
+		// Read the parameter set from the input.
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		parms, err = slhdsa.ParseParameterSet(scanner.Text())
+		if err != nil {
+			return err
+		}
+	}
+	parms.SecurityModel, err = slhdsa.SecurityModelByName(*securityModel)
+	if err != nil {
+		return err
+	}
+
+	t := table.NewWriter()
+	var render func() string
+	switch strings.ToLower(*tableFormat) {
+	case "console":
+		render = t.Render
+	case "markdown":
+		render = t.RenderMarkdown
+	case "csv":
+		render = t.RenderCSV
+	default:
+		return fmt.Errorf("unrecognized table format: %v", *tableFormat)
+	}
+
+	l, err := ledger.Open(*ledgerFile)
+	if err != nil {
+		return err
+	}
+	if *record {
+		usage, err := l.Record(*key, parms, policy)
+		if err != nil {
+			return err
+		}
+		if usage.Action == ledger.Warn {
+			level := parms.TargetSecurityLevel
+			if usage.BelowOveruse {
+				level = parms.OveruseSecurityLevel
+			}
+			fmt.Fprintf(os.Stderr, "warning: key %q is below %v bits of security after %d signatures\n", *key, level, usage.Signatures)
+		}
+	}
+
+	next, err := l.Check(*key, parms, policy)
+	if err != nil {
+		return err
+	}
+	signatures := l.Signatures(*key)
+	t.AppendHeader(table.Row{
+		"key",
+		"sigs",
+		"log_2 sigs",
+		"security level",
+		fmt.Sprintf("sigs left at %v", parms.TargetSecurityLevel),
+		fmt.Sprintf("sigs left at %v", parms.OveruseSecurityLevel),
+		"next sig",
+	})
+	t.AppendRow(table.Row{
+		*key,
+		signatures,
+		fmt.Sprintf("%.2f", math.Log2(float64(signatures))),
+		// A key that has not signed anything yet is as secure as after its first signature
+		fmt.Sprintf("%.2f", parms.SecurityLevel(math.Log2(float64(max(signatures, 1))))),
+		remaining(parms, parms.TargetSecurityLevel, signatures),
+		remaining(parms, parms.OveruseSecurityLevel, signatures),
+		next.Action,
+	})
+
+	t.SetStyle(table.StyleColoredDark)
+	t.Style().Title.Align = text.AlignCenter
+	t.SetTitle("Signature Ledger")
+	fmt.Println(render())
+	return nil
+}
+
+// remaining returns the approximate number of signatures the key can still make while retaining
+// the security level.
+func remaining(p slhdsa.ParameterSet, level int, signatures uint64) uint64 {
+	return uint64(math.Max(0, math.Floor(math.Exp2(p.SignaturesAtLevel(level)))-float64(signatures)))
+}
@@ -0,0 +1,136 @@
+// Package main contains the entry logic for lifetime
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/lifetime"
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+	"golang.org/x/term"
+)
+
+var (
+	tableFormat   = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	parameterSet  = flag.String("parameter_set", "", "name of a standard parameter set, one of ('"+strings.Join(slhdsa.ParameterSetNames(), "', '")+"'); if not set, the parameter set is read from the input")
+	rate          = flag.String("rate", "1/m", "signing rate as COUNT/UNIT (UNIT one of s, m, h, d or y), or a schedule of rates as a comma-separated list of RATE@YEARS, the last of which may omit @YEARS")
+	lifetimeYears = flag.Float64("lifetime_years", 30, "desired lifetime of the key in years")
+	securityModel = flag.String("security_model", slhdsa.DefaultSecurityModel.Name(), "analysis used to compute the security level, one of ('"+strings.Join(slhdsa.SecurityModelNames(), "', '")+"')")
+)
+
+func main() {
+	flag.Parse()
+	extraArgs := flag.Args()
+	if len(extraArgs) != 0 {
+		fmt.Fprintf(os.Stderr, "unrecognized arguments: %v", strings.Join(extraArgs, ", "))
+		os.Exit(1)
+	}
+
+	if err := mainErr(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func mainErr() error {
+	if *lifetimeYears <= 0 {
+		return fmt.Errorf("--lifetime_years must be positive, got %v", *lifetimeYears)
+	}
+	schedule, err := lifetime.ParseSchedule(*rate)
+	if err != nil {
+		return err
+	}
+
+	var parms slhdsa.ParameterSet
+	name := *parameterSet
+	if name != "" {
+		parms, err = slhdsa.ParameterSetByName(name)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Print a prompt if the program is being run from an interactive terminal
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			fmt.Printf("Enter the values (overuse, n, d, h', a, k, lg_w) for the parameter set\n")
+		}
+
+		// Read the parameter set from the input.
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Scan()
+		parms, err = slhdsa.ParseParameterSet(scanner.Text())
+		if err != nil {
+			return err
+		}
+		name = "Parameter Set"
+	}
+	parms.SecurityModel, err = slhdsa.SecurityModelByName(*securityModel)
+	if err != nil {
+		return err
+	}
+
+	t := table.NewWriter()
+	var render func() string
+	switch strings.ToLower(*tableFormat) {
+	case "console":
+		render = t.Render
+	case "markdown":
+		render = t.RenderMarkdown
+	case "csv":
+		render = t.RenderCSV
+	default:
+		return fmt.Errorf("unrecognized table format: %v", *tableFormat)
+	}
+
+	t.AppendHeader(table.Row{
+		"security level",
+		"sigs at level",
+		"years until below level",
+		fmt.Sprintf("years left after %v years", *lifetimeYears),
+		fmt.Sprintf("max sigs/s for %v years", *lifetimeYears),
+		fmt.Sprintf("max sigs/day for %v years", *lifetimeYears),
+	})
+	levels := []int{parms.TargetSecurityLevel, parms.OveruseSecurityLevel}
+	for _, milestone := range lifetime.Plan(parms, schedule, levels) {
+		maxRate := lifetime.MaxRate(parms, milestone.SecurityLevel, *lifetimeYears)
+		t.AppendRow(table.Row{
+			milestone.SecurityLevel,
+			milestone.Signatures,
+			formatYears(milestone.Years),
+			formatYears(milestone.Years - *lifetimeYears),
+			fmt.Sprintf("%.3g", maxRate),
+			fmt.Sprintf("%.3g", maxRate*24*60*60),
+		})
+	}
+
+	// The security level the key is left with at the end of its lifetime
+	// (a key that makes no signatures is as secure as after its first)
+	sigs := math.Log2(math.Max(schedule.Signatures(*lifetimeYears), 1))
+	t.AppendFooter(table.Row{
+		"",
+		fmt.Sprintf("sigs after %v years: %.2f", *lifetimeYears, sigs),
+		"",
+		fmt.Sprintf("security level: %.2f", parms.SecurityLevel(sigs)),
+	})
+
+	t.SetStyle(table.StyleColoredDark)
+	t.Style().Title.Align = text.AlignCenter
+	t.SetTitle(fmt.Sprintf("Key Lifetime of %v", name))
+	fmt.Println(render())
+	return nil
+}
+
+// formatYears formats a number of years, which may be infinite (the level is never crossed) or
+// negative (the level is crossed before the end of the lifetime).
+func formatYears(years float64) string {
+	if math.IsInf(years, 1) {
+		return "never"
+	}
+	return fmt.Sprintf("%.2f", years)
+}
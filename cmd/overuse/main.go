@@ -18,7 +18,9 @@ import (
 )
 
 var (
-	tableFormat = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	tableFormat    = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
+	forgeryBudgets = flag.String("forgery_budgets", "", "comma-separated list of log_2 of attacker hash queries; if set, also print the probability of a forgery for each")
+	securityModel  = flag.String("security_model", slhdsa.DefaultSecurityModel.Name(), "analysis used to compute the security level, one of ('"+strings.Join(slhdsa.SecurityModelNames(), "', '")+"')")
 )
 
 func main() {
@@ -36,36 +38,32 @@ func main() {
 }
 
 func mainErr() error {
-	var parms *slhdsa.ParameterSet
-
 	// Print a prompt if the program is being run from an interactive terminal
 	if term.IsTerminal(int(os.Stdin.Fd())) {
-		fmt.Printf("Enter the values (n, d, h', a, k, lg_w) for the parameter set\n")
+		fmt.Printf("Enter the values (overuse, n, d, h', a, k, lg_w) for the parameter set\n")
 	}
 
 	// Read the parameter set from the input.
 	scanner := bufio.NewScanner(os.Stdin)
 	scanner.Scan()
-	line := scanner.Text()
+	parms, err := slhdsa.ParseParameterSet(scanner.Text())
+	if err != nil {
+		return err
+	}
+	parms.SecurityModel, err = slhdsa.SecurityModelByName(*securityModel)
+	if err != nil {
+		return err
+	}
 
-	var err error
-	parms, err = getParameterSetFromLine(line)
+	budgets, err := parseBudgets(*forgeryBudgets)
 	if err != nil {
 		return err
 	}
 
 	t := table.NewWriter()
-	var render func() string
-	switch strings.ToLower(*tableFormat) {
-	case "console":
-		render = t.Render
-	case "markdown":
-		render = t.RenderMarkdown
-	case "csv":
-		render = t.RenderCSV
-	default:
-		fmt.Fprintf(os.Stderr, "unrecognized table format: %v", *tableFormat)
-		os.Exit(1)
+	render, err := renderer(t)
+	if err != nil {
+		return err
 	}
 
 	t.AppendHeader(table.Row{
@@ -74,62 +72,82 @@ func mainErr() error {
 	})
 
 	// Compute the security level for various values of overuse until it drops below 64 bits
-	sigs := math.Floor(parms.SignaturesAtLevel(parms.TargetSecurityLevel))
-	for {
-		security := parms.SecurityLevel(float64(sigs))
-		if security < 64 {
+	const step = 0.25
+	from := math.Floor(parms.SignaturesAtLevel(parms.TargetSecurityLevel))
+	to := parms.SignaturesAtLevel(64) + step
+	var curve []slhdsa.SecurityPoint
+	for _, point := range parms.SecurityCurve(from, to, step) {
+		if point.Security < 64 {
 			break
 		}
+		curve = append(curve, point)
 		t.AppendRow(table.Row{
-			sigs,
-			security,
+			point.Signatures,
+			point.Security,
 		})
-		sigs += 0.25
 	}
 
 	t.SetStyle(table.StyleColoredDark)
 	t.Style().Title.Align = text.AlignCenter
 	t.SetTitle("Overuse Security Level")
 	fmt.Println(render())
-	return nil
-}
 
-func getParameterSetFromLine(line string) (*slhdsa.ParameterSet, error) {
-	split := strings.Split(line, " ")
-	if len(split) != 6 {
-		return nil, fmt.Errorf("expected format: (n, d, h', a, k, lg_w); got %d fields", len(split))
+	if len(budgets) == 0 {
+		return nil
 	}
-	n, err := strconv.ParseInt(split[0], 10, 32)
+
+	// Compute the probability of a forgery for the same numbers of signatures, for each attacker budget
+	f := table.NewWriter()
+	render, err = renderer(f)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse n from %q: %v", split[2], err)
+		return err
 	}
-	d, err := strconv.ParseInt(split[1], 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse d from %q: %v", split[3], err)
+	header := table.Row{"sigs"}
+	for _, budget := range budgets {
+		header = append(header, fmt.Sprintf("2^%v queries", budget))
 	}
-	hp, err := strconv.ParseInt(split[2], 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse h' from %q: %v", split[4], err)
+	f.AppendHeader(header)
+	for _, point := range curve {
+		row := table.Row{point.Signatures}
+		for _, budget := range budgets {
+			row = append(row, fmt.Sprintf("%.3g", parms.ForgeryProbability(point.Signatures, budget)))
+		}
+		f.AppendRow(row)
 	}
-	a, err := strconv.ParseInt(split[3], 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse a from %q: %v", split[5], err)
+
+	f.SetStyle(table.StyleColoredDark)
+	f.Style().Title.Align = text.AlignCenter
+	f.SetTitle("Forgery Probability")
+	fmt.Println()
+	fmt.Println(render())
+	return nil
+}
+
+// renderer returns the function that renders the table in the requested format.
+func renderer(t table.Writer) (func() string, error) {
+	switch strings.ToLower(*tableFormat) {
+	case "console":
+		return t.Render, nil
+	case "markdown":
+		return t.RenderMarkdown, nil
+	case "csv":
+		return t.RenderCSV, nil
 	}
-	k, err := strconv.ParseInt(split[4], 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse k from %q: %v", split[6], err)
+	return nil, fmt.Errorf("unrecognized table format: %v", *tableFormat)
+}
+
+// parseBudgets parses a comma-separated list of log_2 attacker budgets.
+func parseBudgets(list string) ([]float64, error) {
+	if list == "" {
+		return nil, nil
 	}
-	lgw, err := strconv.ParseInt(split[5], 10, 32)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse lg_w from %q: %v", split[7], err)
+	var budgets []float64
+	for _, field := range strings.Split(list, ",") {
+		budget, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse attacker budget from %q: %v", field, err)
+		}
+		budgets = append(budgets, budget)
 	}
-
-	return &slhdsa.ParameterSet{
-		TargetSecurityLevel: int(n) * 8,
-		D:                   int(d),
-		HPrime:              int(hp),
-		T:                   int(a),
-		K:                   int(k),
-		LgW:                 int(lgw),
-	}, nil
+	return budgets, nil
 }
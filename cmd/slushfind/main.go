@@ -2,12 +2,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"math"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/chrisfenner/slh-dsa-rls/pkg/perf"
 	"github.com/chrisfenner/slh-dsa-rls/pkg/search"
 	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
 	"github.com/jedib0t/go-pretty/table"
@@ -38,30 +43,193 @@ var (
 	verifyCostWeight             = flag.Float64("eval_verify_hashes", 0.5, "how much to consider verification cost in the evaluation function")
 	tableFormat                  = flag.String("table_format", "console", "style for the output, one of ('console', 'markdown', 'csv')")
 	namePrefix                   = flag.String("name_prefix", "", "prefix to use for parameter set ID")
+	workers                      = flag.Int("workers", 0, "number of worker goroutines to search with (defaults to GOMAXPROCS)")
+	exhaustive                   = flag.Bool("exhaustive", false, "evaluate every candidate instead of pruning the search space")
+	timeout                      = flag.Duration("timeout", 0, "stop searching after this long and print the best candidates found so far (0 for no limit)")
+	showProgress                 = flag.Bool("progress", false, "print a progress line to stderr while searching")
+	securityModel                = flag.String("security_model", slhdsa.DefaultSecurityModel.Name(), "analysis used to compute the security level, one of ('"+strings.Join(slhdsa.SecurityModelNames(), "', '")+"')")
+	hashFamily                   = flag.String("hash_family", slhdsa.DefaultHashFamily.Name(), "instantiation of the hash functions that signing and verification costs are counted for, one of ('"+strings.Join(slhdsa.HashFamilyNames(), "', '")+"')")
+	messageBytes                 = flag.Int("message_bytes", slhdsa.DefaultMessageBytes, "length in bytes of the messages that are signed, which signing and verification process (a hash per 64 bytes with --hash_family 'abstract')")
+	preHash                      = flag.Bool("pre_hash", false, "pre-hash messages (HashSLH-DSA), so that signing and verification process them only once")
+	numKeys                      = flag.Int("num_keys", 1, "number of keys that will use the parameter set, each making the minimum numbers of signatures")
+	quantum                      = flag.Bool("quantum", false, "interpret the security levels as security against a quantum attacker (in bits)")
+	overuseTiers                 overuseTierList
+	securityFloor                = flag.String("security_floor", "", "CSV file of (log_2 signatures, security level) breakpoints of a piecewise-linear curve the security level must stay above")
+	calibration                  = flag.String("calibration", "", "profile saved by the calibrate command; if set, signing and verification costs are shown, constrained and ranked as estimated seconds on the calibrated machine")
+	profiles                     = flag.String("profiles", "", "JSON file of additional performance profiles (a profile or a list of them) for --signer_profile and --verifier_profile")
+	signerProfile                = flag.String("signer_profile", "", "performance profile of the signing device, one of ('"+strings.Join(perf.BuiltinProfiles().Names(), "', '")+"') or one from --profiles; if set, signing costs are shown, constrained and ranked as estimated seconds on it")
+	verifierProfile              = flag.String("verifier_profile", "", "performance profile of the verifying device, one of ('"+strings.Join(perf.BuiltinProfiles().Names(), "', '")+"') or one from --profiles; if set, verification costs are shown, constrained and ranked as estimated seconds on it")
+	signCores                    = flag.Int("sign_cores", 1, "number of cores (parallel hash units) that sign, so that signing costs (in the output, the limits and the ranking) are the time to sign with all of them")
+	verifyCost                   = flag.String("verify_cost", "average", "which verification cost (in the output, the limits and the ranking) to count over messages, one of ('average', 'best', 'worst', or a percentile such as 'p99')")
+	cacheLayers                  = flag.Int("cache_layers", -1, "number of layers of the hypertree, counted from the top, that the signer caches, for the cached signing costs (-1 for the whole hypertree)")
+	cacheLevels                  = flag.Int("cache_levels", 0, "with --cache_layers, number of levels of each XMSS tree in the other layers that the signer caches, counted from the top")
+	maxCacheBytes                = flag.Float64("max_cache_bytes", 0, "with --cache_layers, maximum size in bytes of the signer's hypertree cache (0 for no limit)")
+	maxSignSeconds               = flag.Float64("max_sign_seconds", 0, "with a signer profile, maximum estimated seconds to compute a signature (0 for no limit)")
+	maxCachedSignSeconds         = flag.Float64("max_cached_sign_seconds", 0, "with a signer profile, maximum estimated seconds to compute a signature if the hypertree is cached (0 for no limit)")
+	maxVerifySeconds             = flag.Float64("max_verify_seconds", 0, "with a verifier profile, maximum estimated seconds to verify a signature (0 for no limit)")
+	pareto                       = flag.Bool("pareto", false, "print every parameter set on the Pareto frontier of signature size, signing cost, verification cost and overuse signatures, instead of the best few")
 )
 
-func makeCompareFunc(cached bool) func(a, b *slhdsa.ParameterSet) bool {
+func init() {
+	flag.Var(&overuseTiers, "overuse_tier", "additional security level to retain up to a number of signatures, as LEVEL@LOG2SIGS (e.g., 96@48); may be repeated")
+}
+
+// overuseTierList is a flag that collects overuse tiers of the form LEVEL@LOG2SIGS.
+type overuseTierList []search.OveruseTier
+
+func (l *overuseTierList) String() string {
+	if l == nil {
+		return ""
+	}
+	tiers := make([]string, len(*l))
+	for i, tier := range *l {
+		tiers[i] = fmt.Sprintf("%d@%v", tier.SecurityLevel, math.Log2(tier.MinSignatures))
+	}
+	return strings.Join(tiers, ",")
+}
+
+func (l *overuseTierList) Set(value string) error {
+	level, sigs, ok := strings.Cut(value, "@")
+	if !ok {
+		return fmt.Errorf("expected LEVEL@LOG2SIGS, got %q", value)
+	}
+	securityLevel, err := strconv.Atoi(level)
+	if err != nil {
+		return fmt.Errorf("could not parse security level from %q: %v", level, err)
+	}
+	minSignatures, err := strconv.ParseFloat(sigs, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse signature count from %q: %v", sigs, err)
+	}
+	*l = append(*l, search.OveruseTier{SecurityLevel: securityLevel, MinSignatures: math.Exp2(minSignatures)})
+	return nil
+}
+
+// loadSecurityFloor reads a security floor from a CSV file.
+func loadSecurityFloor(path string) (slhdsa.SecurityFloor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return slhdsa.ParseSecurityFloor(f)
+}
+
+// loadProfiles returns the profiles of the signing and verifying devices, either of which is nil if
+// the costs on that side are counted in the units of the hash family. --calibration sets both, unless
+// overridden by --signer_profile or --verifier_profile.
+func loadProfiles(sample slhdsa.ParameterSet) (signer, verifier *perf.Profile, err error) {
+	signer, verifier, err = perf.BuiltinProfiles().Devices(*calibration, *profiles, *signerProfile, *verifierProfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Check that the profiles time every primitive of the hash family
+	for _, prof := range []*perf.Profile{signer, verifier} {
+		if prof == nil {
+			continue
+		}
+		if _, err := prof.SignatureSeconds(sample); err != nil {
+			return nil, nil, fmt.Errorf("profile %q: %v", prof.Name, err)
+		}
+	}
+	if signer == nil && (*maxSignSeconds > 0 || *maxCachedSignSeconds > 0) {
+		return nil, nil, fmt.Errorf("--max_sign_seconds and --max_cached_sign_seconds require --signer_profile or --calibration")
+	}
+	if verifier == nil && *maxVerifySeconds > 0 {
+		return nil, nil, fmt.Errorf("--max_verify_seconds requires --verifier_profile or --calibration")
+	}
+	return signer, verifier, nil
+}
+
+// costModel determines how the costs of signing and verifying are counted.
+type costModel struct {
+	// The profiles of the signing and verifying devices; costs on a side without one are counted in
+	// the units of the hash family
+	signer, verifier *perf.Profile
+	// The number of cores that sign in parallel
+	signCores int
+	// Which parts of the hypertree are cached for the cached signing costs (the whole hypertree if nil)
+	cache *slhdsa.HypertreeCache
+	// The calls to the hash functions counted for verifying
+	verifyCalls func(p slhdsa.ParameterSet) slhdsa.HashCalls
+}
+
+// parseVerifyCost returns the calls to the hash functions to count for verifying, given the value of
+// --verify_cost.
+func parseVerifyCost(value string) (func(p slhdsa.ParameterSet) slhdsa.HashCalls, error) {
+	percentile := 0.0
+	switch value = strings.ToLower(value); {
+	case value == "average":
+		return slhdsa.ParameterSet.VerifyHashCalls, nil
+	case value == "best":
+		percentile = 0
+	case value == "worst":
+		percentile = 100
+	case strings.HasPrefix(value, "p"):
+		var err error
+		percentile, err = strconv.ParseFloat(value[1:], 64)
+		if err != nil || percentile <= 0 || percentile > 100 {
+			return nil, fmt.Errorf("could not parse percentile from %q", value)
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized verify cost: %v", value)
+	}
+	return func(p slhdsa.ParameterSet) slhdsa.HashCalls { return p.VerifyHashCallsAt(percentile) }, nil
+}
+
+// of returns the costs of computing a signature (uncached and cached) and of verifying it: the
+// estimated seconds on the signing or verifying device if there is a profile for it, otherwise in the
+// units of the hash family.
+func (m costModel) of(p *slhdsa.ParameterSet) (sign, cachedSign, verify float64) {
+	sign = float64(p.ParallelSignatureCost(m.signCores))
+	cachedSign = float64(p.ParallelCachedSignatureCost(m.signCores))
+	if m.cache != nil {
+		cachedSign = float64(m.cache.ParallelSignatureCost(*p, m.signCores))
+	}
+	verify = float64(p.Cost(m.verifyCalls(*p)))
+	// The profiles were checked to cover the hash family before the search
+	if m.signer != nil {
+		sign, _ = m.signer.ParallelSignatureSeconds(*p, m.signCores)
+		cachedSign, _ = m.signer.ParallelCachedSignatureSeconds(*p, m.signCores)
+		if m.cache != nil {
+			cachedSign, _ = m.signer.CacheSignatureSeconds(*p, *m.cache, m.signCores)
+		}
+	}
+	if m.verifier != nil {
+		verify, _ = m.verifier.Time(*p, m.verifyCalls(*p))
+	}
+	return sign, cachedSign, verify
+}
+
+func prettySeconds(seconds float64) string {
+	switch {
+	case seconds >= 1:
+		return fmt.Sprintf("%.3gs", seconds)
+	case seconds >= 1e-3:
+		return fmt.Sprintf("%.3gms", seconds*1e3)
+	}
+	return fmt.Sprintf("%.3gµs", seconds*1e6)
+}
+
+func makeCompareFunc(cached bool, costs costModel) func(a, b *slhdsa.ParameterSet) bool {
 	return func(a, b *slhdsa.ParameterSet) bool {
 		var aCost, bCost float64
+		aSign, aCachedSign, aVerify := costs.of(a)
+		bSign, bCachedSign, bVerify := costs.of(b)
 		if *sigSizeWeight != 0 {
 			aCost += *sigSizeWeight * math.Log(float64(a.SignatureSize()))
 			bCost += *sigSizeWeight * math.Log(float64(b.SignatureSize()))
 		}
 		if *sigCostWeight != 0 {
-			var aSigHashes, bSigHashes int64
 			if cached {
-				aSigHashes = a.CachedSignatureHashes()
-				bSigHashes = b.CachedSignatureHashes()
-			} else {
-				aSigHashes = a.SignatureHashes()
-				bSigHashes = b.SignatureHashes()
+				aSign, bSign = aCachedSign, bCachedSign
 			}
-			aCost += *sigCostWeight * math.Log(float64(aSigHashes))
-			bCost += *sigCostWeight * math.Log(float64(bSigHashes))
+			aCost += *sigCostWeight * math.Log(aSign)
+			bCost += *sigCostWeight * math.Log(bSign)
 		}
 		if *verifyCostWeight != 0 {
-			aCost += *verifyCostWeight * math.Log(float64(a.VerifyHashes()))
-			bCost += *verifyCostWeight * math.Log(float64(b.VerifyHashes()))
+			aCost += *verifyCostWeight * math.Log(aVerify)
+			bCost += *verifyCostWeight * math.Log(bVerify)
 		}
 		return aCost < bCost
 	}
@@ -79,6 +247,33 @@ func prettyBigNumber(number int64) string {
 	return fmt.Sprintf("%d", number)
 }
 
+func prettyBytes(bytes float64) string {
+	switch {
+	case bytes >= 1e15:
+		return fmt.Sprintf("%.3gPB", bytes/1e15)
+	case bytes >= 1e12:
+		return fmt.Sprintf("%.3gTB", bytes/1e12)
+	case bytes >= 1e9:
+		return fmt.Sprintf("%.3gGB", bytes/1e9)
+	case bytes >= 1e6:
+		return fmt.Sprintf("%.3gMB", bytes/1e6)
+	case bytes >= 1e3:
+		return fmt.Sprintf("%.3gKB", bytes/1e3)
+	}
+	return fmt.Sprintf("%.0fB", bytes)
+}
+
+// printProgress renders a progress line on stderr, overwriting the previous one.
+func printProgress(progress search.Progress) {
+	line := fmt.Sprintf("evaluated %s/%s candidates (%.1f%%), %s feasible",
+		prettyBigNumber(progress.Evaluated), prettyBigNumber(progress.Total),
+		100*float64(progress.Evaluated)/float64(progress.Total), prettyBigNumber(progress.Feasible))
+	if best := progress.Best; best != nil {
+		line += fmt.Sprintf(", best: h'=%d d=%d a=%d k=%d w=%d (%d bytes)", best.HPrime, best.D, best.T, best.K, best.LgW, best.SignatureSize())
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+}
+
 func main() {
 	flag.Parse()
 	extraArgs := flag.Args()
@@ -101,27 +296,141 @@ func main() {
 		os.Exit(1)
 	}
 
+	model, err := slhdsa.SecurityModelByName(*securityModel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	family, err := slhdsa.HashFamilyByName(*hashFamily)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	// The search itself is in terms of classical security levels
+	targetLevel, overuseLevel := *targetSecurityLevel, *overuseSecurityLevel
+	tiers := slices.Clone(overuseTiers)
+	if *quantum {
+		targetLevel = int(slhdsa.ClassicalSecurity(float64(targetLevel)))
+		overuseLevel = int(slhdsa.ClassicalSecurity(float64(overuseLevel)))
+		for i := range tiers {
+			tiers[i].SecurityLevel = int(slhdsa.ClassicalSecurity(float64(tiers[i].SecurityLevel)))
+		}
+	}
+
+	var floor slhdsa.SecurityFloor
+	if *securityFloor != "" {
+		floor, err = loadSecurityFloor(*securityFloor)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		if *quantum {
+			for i := range floor {
+				floor[i].SecurityLevel = slhdsa.ClassicalSecurity(floor[i].SecurityLevel)
+			}
+		}
+	}
+
+	sample := slhdsa.ParameterSet{TargetSecurityLevel: targetLevel, HPrime: 1, D: 1, LgW: 1, K: 1, T: 1, HashFamily: family}
+	signer, verifier, err := loadProfiles(sample)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if *signCores < 1 {
+		fmt.Fprintf(os.Stderr, "--sign_cores must be at least 1\n")
+		os.Exit(1)
+	}
+	costs := costModel{signer: signer, verifier: verifier, signCores: *signCores}
+	costs.verifyCalls, err = parseVerifyCost(*verifyCost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	switch {
+	case *cacheLayers >= 0:
+		costs.cache = &slhdsa.HypertreeCache{Layers: *cacheLayers, Levels: *cacheLevels}
+	case *cacheLevels > 0 || *maxCacheBytes > 0:
+		fmt.Fprintf(os.Stderr, "--cache_levels and --max_cache_bytes require --cache_layers\n")
+		os.Exit(1)
+	}
+
+	// Show the signatures at the overuse security level, and at each additional tier's level
+	levels := []int{*overuseSecurityLevel}
+	for _, tier := range overuseTiers {
+		if !slices.Contains(levels, tier.SecurityLevel) {
+			levels = append(levels, tier.SecurityLevel)
+		}
+	}
+
 	searchParams := search.Parameters{
-		TargetSecurityLevel:   *targetSecurityLevel,
-		MinSignatures:         math.Exp2(*minSignatureCount),
-		OveruseSecurityLevel:  *overuseSecurityLevel,
-		MinOveruseSignatures:  math.Exp2(*minOveruseSignatureCount),
-		HPrime:                intsBetween(1, 30),
-		D:                     intsBetween(1, 30),
-		LgW:                   intsBetween(1, 8),
-		K:                     intsBetween(1, 30),
-		T:                     intsBetween(1, 30),
-		SignatureSize:         func(sz int) bool { return sz <= *maxSignatureSize },
-		SignatureHashes:       func(hashes int64) bool { return *minSignatureHashes < hashes && hashes < *maxSignatureHashes },
-		CachedSignatureHashes: func(hashes int64) bool { return hashes < *maxCachedSignatureHashes },
-		VerifyHashes:          func(hashes int64) bool { return hashes < *maxVerifyHashes },
-		Compare:               makeCompareFunc(*compareCachedSignatureHashes),
-		CandidateCount:        20,
-	}
-
-	results := search.Search(&searchParams)
-
-	t.AppendHeader(table.Row{
+		TargetSecurityLevel:      targetLevel,
+		MinSignatures:            math.Exp2(*minSignatureCount),
+		OveruseSecurityLevel:     overuseLevel,
+		MinOveruseSignatures:     math.Exp2(*minOveruseSignatureCount),
+		OveruseTiers:             tiers,
+		SecurityFloor:            floor,
+		NumKeys:                  *numKeys,
+		HPrime:                   intsBetween(1, 30),
+		D:                        intsBetween(1, 30),
+		LgW:                      intsBetween(1, 8),
+		K:                        intsBetween(1, 30),
+		T:                        intsBetween(1, 30),
+		SecurityModel:            model,
+		HashFamily:               family,
+		MessageBytes:             *messageBytes,
+		PreHash:                  *preHash,
+		SignCores:                costs.signCores,
+		Cache:                    costs.cache,
+		MaxCacheBytes:            *maxCacheBytes,
+		VerifyCost:               func(p *slhdsa.ParameterSet) int64 { return p.Cost(costs.verifyCalls(*p)) },
+		MaxSignatureSize:         *maxSignatureSize,
+		MaxSignatureHashes:       *maxSignatureHashes,
+		MaxCachedSignatureHashes: *maxCachedSignatureHashes,
+		MaxVerifyHashes:          *maxVerifyHashes,
+		Exhaustive:               *exhaustive,
+		SignatureHashes:          func(hashes int64) bool { return *minSignatureHashes < hashes && hashes < *maxSignatureHashes },
+		CachedSignatureHashes:    func(hashes int64) bool { return hashes < *maxCachedSignatureHashes },
+		VerifyHashes:             func(hashes int64) bool { return hashes < *maxVerifyHashes },
+		Compare:                  makeCompareFunc(*compareCachedSignatureHashes, costs),
+		CandidateCount:           20,
+		Workers:                  *workers,
+	}
+	if signer != nil || verifier != nil {
+		searchParams.Filter = func(candidate *slhdsa.ParameterSet) bool {
+			sign, cachedSign, verify := costs.of(candidate)
+			return (*maxSignSeconds <= 0 || sign <= *maxSignSeconds) &&
+				(*maxCachedSignSeconds <= 0 || cachedSign <= *maxCachedSignSeconds) &&
+				(*maxVerifySeconds <= 0 || verify <= *maxVerifySeconds)
+		}
+	}
+	if *showProgress {
+		searchParams.Progress = printProgress
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var results []slhdsa.ParameterSet
+	if *pareto {
+		results, err = search.ParetoContext(ctx, &searchParams)
+	} else {
+		results, err = search.SearchContext(ctx, &searchParams)
+	}
+	if *showProgress {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "search stopped early (%v); showing the best candidates found so far\n", err)
+	}
+
+	header := table.Row{
 		"id",
 		"h",
 		"d",
@@ -134,12 +443,24 @@ func main() {
 		"sign time",
 		"sign cached",
 		"verify time",
-		fmt.Sprintf("sigs at %v", *overuseSecurityLevel),
-	})
+	}
+	if costs.cache != nil {
+		header = append(header, "cache bytes")
+	}
+	for _, level := range levels {
+		header = append(header, fmt.Sprintf("sigs at %v", level))
+	}
+	if *numKeys > 1 {
+		header = append(header, fmt.Sprintf("fleet sigs at %v", *targetSecurityLevel))
+	}
+	if *quantum {
+		header = append(header, "category")
+	}
+	t.AppendHeader(header)
 
 	for i, result := range results {
 		id := fmt.Sprintf("%s%d", *namePrefix, i+1)
-		t.AppendRow(table.Row{
+		row := table.Row{
 			id,                       // "i",
 			result.HypertreeHeight(), // "h",
 			result.D,                 // "d",
@@ -149,19 +470,104 @@ func main() {
 			result.LgW,               // "lg_w",
 			result.M(),               // "m",
 			result.SignatureSize(),   // "sig bytes",
-			prettyBigNumber(result.SignatureHashes()),       // "sign time",
-			prettyBigNumber(result.CachedSignatureHashes()), // "sign cached",
-			result.VerifyHashes(),                           // "verify time",
-			result.SignaturesAtLevel(*overuseSecurityLevel), // "sigs at {fallbackSecurityLevel}",
-		})
+		}
+		sign, cachedSign, verify := costs.of(&result)
+		if signer != nil {
+			row = append(row,
+				prettySeconds(sign),       // "sign time",
+				prettySeconds(cachedSign), // "sign cached",
+			)
+		} else {
+			row = append(row,
+				prettyBigNumber(int64(sign)),       // "sign time",
+				prettyBigNumber(int64(cachedSign)), // "sign cached",
+			)
+		}
+		if verifier != nil {
+			row = append(row, prettySeconds(verify)) // "verify time",
+		} else {
+			row = append(row, int64(verify)) // "verify time",
+		}
+		if costs.cache != nil {
+			row = append(row, prettyBytes(costs.cache.Bytes(result))) // "cache bytes",
+		}
+		for _, level := range levels {
+			if *quantum {
+				level = int(slhdsa.ClassicalSecurity(float64(level)))
+			}
+			row = append(row, result.SignaturesAtLevel(level)) // "sigs at {level}",
+		}
+		if *numKeys > 1 {
+			row = append(row, result.FleetSignaturesAtLevel(targetLevel, *numKeys)) // "fleet sigs at {targetSecurityLevel}",
+		}
+		if *quantum {
+			row = append(row, slhdsa.NISTCategoryOf(result.QuantumSecurityLevel(*minSignatureCount))) // "category",
+		}
+		t.AppendRow(row)
 	}
 
 	t.SetStyle(table.StyleColoredDark)
 	t.Style().Title.Align = text.AlignCenter
 	title := fmt.Sprintf("Target security level %d, 2^%.0f signatures", *targetSecurityLevel, *minSignatureCount)
+	if *quantum {
+		title = fmt.Sprintf("Target quantum security level %d, 2^%.0f signatures", *targetSecurityLevel, *minSignatureCount)
+	}
 	if *minOveruseSignatureCount > 0 {
 		title += fmt.Sprintf(" (level %d @ 2^%.0f signatures)", *overuseSecurityLevel, *minOveruseSignatureCount)
 	}
+	for _, tier := range overuseTiers {
+		title += fmt.Sprintf(" (level %d @ 2^%.0f signatures)", tier.SecurityLevel, math.Log2(tier.MinSignatures))
+	}
+	if *securityFloor != "" {
+		title += fmt.Sprintf(" (floor %s)", *securityFloor)
+	}
+	if *numKeys > 1 {
+		title += fmt.Sprintf(", %d keys", *numKeys)
+	}
+	switch {
+	case signer != nil && signer == verifier:
+		title += fmt.Sprintf(", times on %s", signer.Machine)
+	case signer != nil || verifier != nil:
+		if signer != nil {
+			title += fmt.Sprintf(", signing on %s", signer.Machine)
+		}
+		if verifier != nil {
+			title += fmt.Sprintf(", verifying on %s", verifier.Machine)
+		}
+		if family != slhdsa.DefaultHashFamily && (signer == nil || verifier == nil) {
+			title += fmt.Sprintf(", other costs in %s", family.Unit())
+		}
+	case family != slhdsa.DefaultHashFamily:
+		title += fmt.Sprintf(", costs in %s", family.Unit())
+	}
+	if *messageBytes != slhdsa.DefaultMessageBytes || *preHash {
+		title += fmt.Sprintf(", %d-byte messages", *messageBytes)
+		if *preHash {
+			title += " (pre-hashed)"
+		}
+	}
+	switch value := strings.ToLower(*verifyCost); value {
+	case "average":
+	case "best", "worst":
+		title += fmt.Sprintf(", %s-case verification", value)
+	default:
+		title += fmt.Sprintf(", %s verification", value)
+	}
+	if costs.cache != nil {
+		title += fmt.Sprintf(", caching %d hypertree layers", costs.cache.Layers)
+		if costs.cache.Levels > 0 {
+			title += fmt.Sprintf(" and %d levels of the rest", costs.cache.Levels)
+		}
+	}
+	if costs.signCores > 1 {
+		title += fmt.Sprintf(", %d signing cores", costs.signCores)
+	}
+	if *pareto {
+		title = "Pareto frontier: " + title
+	}
+	if err != nil {
+		title += " (incomplete)"
+	}
 	t.SetTitle(title)
 	fmt.Println(render())
 }
@@ -0,0 +1,262 @@
+// Package ledger keeps a durable count of the signatures issued by each SLH-DSA key, and enforces its
+// security budget before every signature.
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// Action is what a Ledger does when a signature would push a key below a security level.
+type Action int
+
+const (
+	// Record the signature silently
+	Allow Action = iota
+	// Record the signature, but report that the key is below the level
+	Warn
+	// Do not record the signature, and return ErrRefused
+	Refuse
+)
+
+var actionNames = []string{"allow", "warn", "refuse"}
+
+func (a Action) String() string {
+	if a < 0 || int(a) >= len(actionNames) {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+	return actionNames[a]
+}
+
+// ParseAction returns the action with the given name (one of "allow", "warn" or "refuse").
+func ParseAction(name string) (Action, error) {
+	for i, known := range actionNames {
+		if strings.EqualFold(known, name) {
+			return Action(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown action %q (known actions: %v)", name, strings.Join(actionNames, ", "))
+}
+
+// Policy is what a Ledger does when a signature would push a key below each of its security levels.
+type Policy struct {
+	// The action when the key would drop below its TargetSecurityLevel
+	BelowTarget Action
+	// The action when the key would drop below its OveruseSecurityLevel
+	BelowOveruse Action
+}
+
+// DefaultPolicy warns once a key drops below its target security level, and refuses to let it drop
+// below its overuse security level.
+var DefaultPolicy = Policy{BelowTarget: Warn, BelowOveruse: Refuse}
+
+// ErrRefused is returned (wrapped) by Record when the policy refuses a signature.
+var ErrRefused = errors.New("signature refused")
+
+// Usage is the state of a key after a call to Record.
+type Usage struct {
+	// The number of signatures issued by the key, including the one just recorded
+	Signatures uint64
+	// The security level (in bits) of the key after that many signatures
+	SecurityLevel float64
+	// Whether the key is below its TargetSecurityLevel
+	BelowTarget bool
+	// Whether the key is below its OveruseSecurityLevel
+	BelowOveruse bool
+	// The action the policy took
+	Action Action
+}
+
+// The version of the file format written by a Ledger
+const fileVersion = 1
+
+// The contents of a ledger file
+type ledgerFile struct {
+	Version int `json:"version"`
+	// The number of signatures issued by each key, by key ID
+	Keys map[string]uint64 `json:"keys"`
+}
+
+// Ledger is a file-backed count of the signatures issued by each key.
+// Every update is written to a temporary file, synced and then renamed over the ledger file, so a crash
+// leaves either the old or the new count, never a partial one. Each check and update holds an exclusive
+// lock on a sidecar ".lock" file and re-reads the ledger file under it, so any number of Ledgers, in
+// any number of processes, may update the same file at once without losing counts. A Ledger is safe
+// for concurrent use.
+type Ledger struct {
+	path string
+
+	mu   sync.Mutex
+	keys map[string]uint64
+}
+
+// Open opens the ledger stored at path, which is created on the first update if it does not exist.
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{path: path}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// load reads the counts from the ledger file, if it exists. If the file cannot be read or parsed, the
+// counts are left as they were.
+func (l *Ledger) load() error {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		l.keys = map[string]uint64{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file ledgerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("could not parse ledger %v: %v", l.path, err)
+	}
+	if file.Version != fileVersion {
+		return fmt.Errorf("ledger %v has unsupported version %d", l.path, file.Version)
+	}
+	if file.Keys == nil {
+		file.Keys = map[string]uint64{}
+	}
+	l.keys = file.Keys
+	return nil
+}
+
+// reload locks the ledger file and re-reads the counts from it, since other processes may have
+// recorded signatures since it was last read. The returned function releases the lock.
+func (l *Ledger) reload() (unlock func(), err error) {
+	unlock, err = lockFile(l.path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("could not lock ledger %v: %v", l.path, err)
+	}
+	if err := l.load(); err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// Signatures returns the number of signatures recorded for the key.
+func (l *Ledger) Signatures(key string) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.keys[key]
+}
+
+// Keys returns the IDs of the keys in the ledger, in sorted order.
+func (l *Ledger) Keys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Sorted(maps.Keys(l.keys))
+}
+
+// Check returns the state the key would be in after one more signature, without recording it.
+func (l *Ledger) Check(key string, p slhdsa.ParameterSet, policy Policy) (Usage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := l.reload()
+	if err != nil {
+		return Usage{}, err
+	}
+	defer unlock()
+	return check(l.keys[key]+1, p, policy), nil
+}
+
+// Record records one more signature for the key, which uses the parameter set, and durably stores
+// the new count before returning. This should be called before every signing operation.
+// If the policy refuses the signature, the count is unchanged and the error wraps ErrRefused.
+func (l *Ledger) Record(key string, p slhdsa.ParameterSet, policy Policy) (Usage, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := l.reload()
+	if err != nil {
+		return Usage{}, err
+	}
+	defer unlock()
+
+	usage := check(l.keys[key]+1, p, policy)
+	if usage.Action == Refuse {
+		return usage, fmt.Errorf("%w: key %q would have security level %.2f after %d signatures", ErrRefused, key, usage.SecurityLevel, usage.Signatures)
+	}
+
+	previous, existed := l.keys[key]
+	l.keys[key] = usage.Signatures
+	if err := l.save(); err != nil {
+		// The signature must not be made if its count could not be stored
+		if existed {
+			l.keys[key] = previous
+		} else {
+			delete(l.keys, key)
+		}
+		return usage, err
+	}
+	return usage, nil
+}
+
+// check returns the state of a key after the given number of signatures.
+func check(signatures uint64, p slhdsa.ParameterSet, policy Policy) Usage {
+	usage := Usage{
+		Signatures:    signatures,
+		SecurityLevel: p.SecurityLevel(math.Log2(float64(signatures))),
+	}
+	usage.BelowTarget = usage.SecurityLevel < float64(p.TargetSecurityLevel)
+	usage.BelowOveruse = usage.SecurityLevel < float64(p.OveruseSecurityLevel)
+	if usage.BelowTarget {
+		usage.Action = max(usage.Action, policy.BelowTarget)
+	}
+	if usage.BelowOveruse {
+		usage.Action = max(usage.Action, policy.BelowOveruse)
+	}
+	return usage
+}
+
+// save atomically replaces the ledger file with the current counts.
+func (l *Ledger) save() error {
+	data, err := json.MarshalIndent(ledgerFile{Version: fileVersion, Keys: l.keys}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(l.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(l.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return err
+	}
+
+	// Sync the directory so that the rename itself survives a crash
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
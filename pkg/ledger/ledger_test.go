@@ -0,0 +1,202 @@
+package ledger
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	p, err := slhdsa.ParameterSetByName("rls128cs1")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	for i := uint64(1); i <= 3; i++ {
+		usage, err := l.Record("a", p, DefaultPolicy)
+		if err != nil {
+			t.Fatalf("Record() = %v", err)
+		}
+		if usage.Signatures != i || usage.Action != Allow || usage.SecurityLevel != float64(p.TargetSecurityLevel) {
+			t.Errorf("Record() = %+v, want %v signatures at the target security level", usage, i)
+		}
+	}
+	if _, err := l.Record("b", p, DefaultPolicy); err != nil {
+		t.Fatalf("Record() = %v", err)
+	}
+
+	// The counts survive reopening the ledger
+	l, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	if got := l.Signatures("a"); got != 3 {
+		t.Errorf("Signatures(a) = %v, want 3", got)
+	}
+	if got := l.Keys(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Keys() = %v, want [a b]", got)
+	}
+	if matches, _ := filepath.Glob(path + ".tmp*"); len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	p, err := slhdsa.ParameterSetByName("rls128cs1")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	other, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	for range 2 {
+		if _, err := other.Record("a", p, DefaultPolicy); err != nil {
+			t.Fatalf("Record() = %v", err)
+		}
+	}
+
+	// Check sees the signatures recorded by the other ledger
+	if usage, err := l.Check("a", p, DefaultPolicy); err != nil || usage.Signatures != 3 {
+		t.Errorf("Check() = %+v, %v, want 3 signatures", usage, err)
+	}
+
+	// A ledger file that cannot be parsed leaves the counts as they were
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if _, err := l.Check("a", p, DefaultPolicy); err == nil {
+		t.Errorf("Check() = nil, want an error for a corrupt ledger")
+	}
+	if _, err := l.Record("a", p, DefaultPolicy); err == nil {
+		t.Errorf("Record() = nil, want an error for a corrupt ledger")
+	}
+	if got := l.Signatures("a"); got != 2 {
+		t.Errorf("Signatures(a) = %v, want 2", got)
+	}
+}
+
+func TestConcurrentRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	p, err := slhdsa.ParameterSetByName("rls128cs1")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+
+	// Each writer opens the file itself, as a separate process would
+	const writers, signatures = 8, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*signatures)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range signatures {
+				l, err := Open(path)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if _, err := l.Record("a", p, DefaultPolicy); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Record() = %v", err)
+	}
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	if got := l.Signatures("a"); got != writers*signatures {
+		t.Errorf("Signatures(a) = %v, want %v", got, writers*signatures)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	p, err := slhdsa.ParameterSetByName("rls128cs1")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	// Signature counts just inside and just past each security level
+	const margin = 0.05
+	target := p.SignaturesAtLevel(p.TargetSecurityLevel)
+	overuse := p.SignaturesAtLevel(p.OveruseSecurityLevel)
+
+	for _, tc := range []struct {
+		name       string
+		signatures float64
+		policy     Policy
+		action     Action
+	}{
+		{"within target", target - margin, DefaultPolicy, Allow},
+		{"below target", target + margin, DefaultPolicy, Warn},
+		{"below target, refused", target + margin, Policy{BelowTarget: Refuse}, Refuse},
+		{"within overuse", overuse - margin, DefaultPolicy, Warn},
+		{"below overuse", overuse + margin, DefaultPolicy, Refuse},
+		{"below overuse, allowed", overuse + margin, Policy{}, Allow},
+		{"below overuse, warned", overuse + margin, Policy{BelowOveruse: Warn}, Warn},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Start the ledger just before the signature being tested
+			path := filepath.Join(t.TempDir(), "ledger.json")
+			before := uint64(math.Exp2(tc.signatures)) - 1
+			contents := `{"version": 1, "keys": {"k": ` + strconv.FormatUint(before, 10) + `}}`
+			if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+				t.Fatalf("WriteFile() = %v", err)
+			}
+			l, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open() = %v", err)
+			}
+
+			if got, err := l.Check("k", p, tc.policy); err != nil || got.Action != tc.action {
+				t.Errorf("Check() = %+v, %v, want action %v", got, err, tc.action)
+			}
+			usage, err := l.Record("k", p, tc.policy)
+			if usage.Action != tc.action {
+				t.Errorf("Record() = %+v, want action %v", usage, tc.action)
+			}
+			want := before + 1
+			if tc.action == Refuse {
+				if !errors.Is(err, ErrRefused) {
+					t.Errorf("Record() = %v, want ErrRefused", err)
+				}
+				want = before
+			} else if err != nil {
+				t.Errorf("Record() = %v", err)
+			}
+
+			// The refused signature is not recorded
+			l, err = Open(path)
+			if err != nil {
+				t.Fatalf("Open() = %v", err)
+			}
+			if got := l.Signatures("k"); got != want {
+				t.Errorf("Signatures() = %v, want %v", got, want)
+			}
+		})
+	}
+}
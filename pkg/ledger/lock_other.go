@@ -0,0 +1,10 @@
+//go:build !unix
+
+package ledger
+
+import "errors"
+
+// lockFile is not supported on this platform, since a ledger cannot be updated safely without it.
+func lockFile(path string) (unlock func(), err error) {
+	return nil, errors.New("file locking is not supported on this platform")
+}
@@ -0,0 +1,155 @@
+package lifetime
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// ReadSigningLog reads the times of past signatures from a log with one signature per line. Each line
+// is either a CSV record whose first field is the time, or a JSON object with the time in its
+// "timestamp" field. Times are RFC 3339 or (possibly fractional) seconds since the Unix epoch. The
+// first line may be a CSV header. The times are returned in increasing order.
+func ReadSigningLog(r io.Reader) ([]time.Time, error) {
+	var times []time.Time
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var field string
+		if strings.HasPrefix(text, "{") {
+			var entry struct {
+				Timestamp json.RawMessage `json:"timestamp"`
+			}
+			if err := json.Unmarshal([]byte(text), &entry); err != nil {
+				return nil, fmt.Errorf("could not parse line %d of signing log: %v", line, err)
+			}
+			if entry.Timestamp == nil {
+				return nil, fmt.Errorf("line %d of signing log has no timestamp", line)
+			}
+			// The timestamp may be a JSON string or number
+			if err := json.Unmarshal(entry.Timestamp, &field); err != nil {
+				field = string(entry.Timestamp)
+			}
+		} else {
+			record, err := csv.NewReader(strings.NewReader(text)).Read()
+			if err != nil {
+				return nil, fmt.Errorf("could not parse line %d of signing log: %v", line, err)
+			}
+			field = record[0]
+		}
+
+		t, err := parseTime(strings.TrimSpace(field))
+		if err != nil && len(times) == 0 && line == 1 {
+			// Skip the header
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse time on line %d of signing log: %v", line, err)
+		}
+		times = append(times, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(times, time.Time.Compare)
+	return times, nil
+}
+
+// parseTime parses an RFC 3339 time or a number of seconds since the Unix epoch.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a Unix time", s)
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC(), nil
+}
+
+// FitRate returns the signing rate (in signatures per second) that best fits the times of the
+// signatures, as the least-squares slope of the number of signatures made against time.
+func FitRate(times []time.Time) (float64, error) {
+	if len(times) < 2 || !times[len(times)-1].After(times[0]) {
+		return 0, errors.New("fitting a signing rate needs signatures at two or more different times")
+	}
+	// Fit relative to the first signature to keep the sums well-conditioned
+	n := float64(len(times))
+	var sumT, sumTT, sumC, sumTC float64
+	for i, t := range times {
+		x := t.Sub(times[0]).Seconds()
+		c := float64(i + 1)
+		sumT += x
+		sumTT += x * x
+		sumC += c
+		sumTC += x * c
+	}
+	return (n*sumTC - sumT*sumC) / (n*sumTT - sumT*sumT), nil
+}
+
+// Forecast is the projected point at which a key drops below a security level.
+type Forecast struct {
+	// The security level in bits
+	SecurityLevel int
+	// The log_2 of the number of signatures that can be made while retaining the security level
+	Signatures float64
+	// The fraction of those signatures that have already been made
+	Used float64
+	// When the key drops (or dropped) below the security level, if Never is false
+	Date time.Time
+	// Whether the key never drops below the security level at the projected rate
+	Never bool
+}
+
+// Project forecasts when a key that made signatures at the given times, and continues to sign at the
+// given rate (in signatures per second), drops below each of the security levels. A key that has
+// made no signatures starts signing now.
+func Project(p slhdsa.ParameterSet, times []time.Time, rate float64, levels []int) []Forecast {
+	forecasts := make([]Forecast, len(levels))
+	for i, level := range levels {
+		signatures := p.SignaturesAtLevel(level)
+		budget := math.Floor(math.Exp2(signatures))
+		forecast := Forecast{
+			SecurityLevel: level,
+			Signatures:    signatures,
+			Used:          float64(len(times)) / budget,
+		}
+		switch {
+		case float64(len(times)) > budget:
+			// The signature that took the key below the level has already been made
+			forecast.Date = times[int(budget)]
+		case rate <= 0:
+			forecast.Never = true
+		default:
+			last := time.Now()
+			if len(times) > 0 {
+				last = times[len(times)-1]
+			}
+			seconds := (budget + 1 - float64(len(times))) / rate
+			if seconds >= 1<<62 {
+				// Beyond what a time.Time can represent
+				forecast.Never = true
+				break
+			}
+			whole, frac := math.Modf(seconds)
+			forecast.Date = time.Unix(last.Unix()+int64(whole), int64(last.Nanosecond())+int64(frac*1e9)).In(last.Location())
+		}
+		forecasts[i] = forecast
+	}
+	return forecasts
+}
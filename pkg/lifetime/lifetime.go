@@ -0,0 +1,147 @@
+// Package lifetime plans how long an SLH-DSA key can be used for at a given signing rate.
+package lifetime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// The number of seconds in a (Julian) year
+const SecondsPerYear = 365.25 * 24 * 60 * 60
+
+// The number of seconds in each of the units a rate can be given per
+var rateUnits = map[string]float64{
+	"s": 1,
+	"m": 60,
+	"h": 60 * 60,
+	"d": 24 * 60 * 60,
+	"y": SecondsPerYear,
+}
+
+// Period is a span of time during which signatures are made at a constant rate.
+type Period struct {
+	// How long the period lasts for, in years (ignored for the last period of a schedule, which
+	// lasts forever)
+	Years float64
+	// The number of signatures per second
+	Rate float64
+}
+
+// Schedule is a sequence of signing rates, starting from when the key is created.
+type Schedule []Period
+
+// ParseRate parses a signing rate of the form COUNT/UNIT (e.g., "1/m" for one signature per minute),
+// where UNIT is one of s, m, h, d or y. A plain COUNT is per second.
+func ParseRate(rate string) (float64, error) {
+	count, unit, ok := strings.Cut(rate, "/")
+	seconds := 1.0
+	if ok {
+		var known bool
+		seconds, known = rateUnits[unit]
+		if !known {
+			return 0, fmt.Errorf("unknown unit %q in rate %q (expected one of s, m, h, d or y)", unit, rate)
+		}
+	}
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse rate from %q: %v", rate, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("rate %q is negative", rate)
+	}
+	return n / seconds, nil
+}
+
+// ParseSchedule parses a comma-separated list of rates, each of the form RATE@YEARS (e.g.,
+// "1/m@5,10/m"), where RATE is as in ParseRate. The last rate may omit @YEARS, and continues forever.
+func ParseSchedule(schedule string) (Schedule, error) {
+	var result Schedule
+	fields := strings.Split(schedule, ",")
+	for i, field := range fields {
+		rate, years, limited := strings.Cut(strings.TrimSpace(field), "@")
+		var period Period
+		var err error
+		if period.Rate, err = ParseRate(rate); err != nil {
+			return nil, err
+		}
+		switch {
+		case limited:
+			if period.Years, err = strconv.ParseFloat(years, 64); err != nil {
+				return nil, fmt.Errorf("could not parse years from %q: %v", field, err)
+			}
+			if period.Years <= 0 {
+				return nil, fmt.Errorf("period %q does not last for a positive number of years", field)
+			}
+		case i != len(fields)-1:
+			return nil, fmt.Errorf("only the last period of a schedule may last forever, got %q", field)
+		}
+		result = append(result, period)
+	}
+	return result, nil
+}
+
+// Signatures returns the number of signatures made in the given number of years.
+func (s Schedule) Signatures(years float64) float64 {
+	var signatures float64
+	for i, period := range s {
+		if i == len(s)-1 || years <= period.Years {
+			return signatures + period.Rate*years*SecondsPerYear
+		}
+		signatures += period.Rate * period.Years * SecondsPerYear
+		years -= period.Years
+	}
+	return signatures
+}
+
+// YearsUntil returns the number of years until the given number of signatures have been made
+// (+Inf if they never are).
+func (s Schedule) YearsUntil(signatures float64) float64 {
+	var years float64
+	for i, period := range s {
+		made := period.Rate * period.Years * SecondsPerYear
+		if i == len(s)-1 || signatures <= made {
+			if period.Rate == 0 {
+				break
+			}
+			return years + signatures/(period.Rate*SecondsPerYear)
+		}
+		signatures -= made
+		years += period.Years
+	}
+	return math.Inf(1)
+}
+
+// Milestone is the point at which a key drops below a security level.
+type Milestone struct {
+	// The security level in bits
+	SecurityLevel int
+	// The log_2 of the number of signatures that can be made while retaining the security level
+	Signatures float64
+	// The number of years after which the key drops below the security level (+Inf if it never does)
+	Years float64
+}
+
+// Plan returns the milestone at which a key using the schedule drops below each of the security
+// levels.
+func Plan(p slhdsa.ParameterSet, schedule Schedule, levels []int) []Milestone {
+	milestones := make([]Milestone, len(levels))
+	for i, level := range levels {
+		signatures := p.SignaturesAtLevel(level)
+		milestones[i] = Milestone{
+			SecurityLevel: level,
+			Signatures:    signatures,
+			Years:         schedule.YearsUntil(math.Exp2(signatures)),
+		}
+	}
+	return milestones
+}
+
+// MaxRate returns the highest constant signing rate (in signatures per second) at which a key
+// retains the security level for the given number of years.
+func MaxRate(p slhdsa.ParameterSet, level int, years float64) float64 {
+	return math.Exp2(p.SignaturesAtLevel(level)) / (years * SecondsPerYear)
+}
@@ -0,0 +1,183 @@
+package lifetime
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		schedule string
+		want     Schedule
+	}{
+		{"2", Schedule{{Rate: 2}}},
+		{"1/m", Schedule{{Rate: 1.0 / 60}}},
+		{"24/d@5, 1/h", Schedule{{Years: 5, Rate: 1.0 / 3600}, {Rate: 1.0 / 3600}}},
+		{"1/s@1,0/s@2,1/y", Schedule{{Years: 1, Rate: 1}, {Years: 2}, {Rate: 1 / SecondsPerYear}}},
+	} {
+		t.Run(tc.schedule, func(t *testing.T) {
+			got, err := ParseSchedule(tc.schedule)
+			if err != nil {
+				t.Fatalf("ParseSchedule() = %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("ParseSchedule() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i].Years != tc.want[i].Years || math.Abs(got[i].Rate-tc.want[i].Rate) > 1e-12 {
+					t.Errorf("ParseSchedule() = %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+
+	for _, schedule := range []string{"", "1/w", "-1/s", "1/s,2/s", "1/s@0,2/s", "1/s@x"} {
+		if _, err := ParseSchedule(schedule); err == nil {
+			t.Errorf("ParseSchedule(%q) = nil, want error", schedule)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	// One signature per second for a year, none for a year, then two per second
+	schedule := Schedule{{Years: 1, Rate: 1}, {Years: 1}, {Rate: 2}}
+	for _, tc := range []struct {
+		years      float64
+		signatures float64
+	}{
+		{0.5, 0.5 * SecondsPerYear},
+		{1, SecondsPerYear},
+		{3, 3 * SecondsPerYear},
+	} {
+		if got := schedule.Signatures(tc.years); math.Abs(got-tc.signatures) > 1e-6 {
+			t.Errorf("Signatures(%v) = %v, want %v", tc.years, got, tc.signatures)
+		}
+		if tc.years == 1 {
+			// The signature count does not change in the second year
+			continue
+		}
+		if got := schedule.YearsUntil(tc.signatures); math.Abs(got-tc.years) > 1e-9 {
+			t.Errorf("YearsUntil(%v) = %v, want %v", tc.signatures, got, tc.years)
+		}
+	}
+
+	if got := (Schedule{{Years: 1, Rate: 1}, {}}).YearsUntil(2 * SecondsPerYear); !math.IsInf(got, 1) {
+		t.Errorf("YearsUntil() = %v, want +Inf", got)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	p, err := slhdsa.ParameterSetByName("rls128cs1")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	schedule := Schedule{{Rate: 1.0 / 60}}
+	milestones := Plan(p, schedule, []int{p.TargetSecurityLevel, p.OveruseSecurityLevel})
+	if len(milestones) != 2 {
+		t.Fatalf("Plan() = %v, want 2 milestones", milestones)
+	}
+	for _, milestone := range milestones {
+		if got := schedule.Signatures(milestone.Years); math.Abs(math.Log2(got)-milestone.Signatures) > 1e-9 {
+			t.Errorf("%v: signatures after %v years = 2^%v, want 2^%v", milestone.SecurityLevel, milestone.Years, math.Log2(got), milestone.Signatures)
+		}
+
+		// Signing at the maximum rate for the lifetime uses exactly the signatures available
+		maxRate := MaxRate(p, milestone.SecurityLevel, milestone.Years)
+		if math.Abs(maxRate-schedule[0].Rate) > 1e-12 {
+			t.Errorf("%v: MaxRate() = %v, want %v", milestone.SecurityLevel, maxRate, schedule[0].Rate)
+		}
+	}
+	if milestones[0].Years >= milestones[1].Years {
+		t.Errorf("Plan() = %v, want the target level to be crossed before the overuse level", milestones)
+	}
+}
+
+func TestReadSigningLog(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	want := []time.Time{start, start.Add(time.Minute), start.Add(90 * time.Second)}
+	for _, tc := range []struct {
+		name string
+		log  string
+	}{
+		{"csv", "time,key\n2025-01-01T00:01:00Z,a\n2025-01-01T00:00:00Z,a\n\n2025-01-01T00:01:30Z,a\n"},
+		{"unix", "1735689660\n1735689600\n1735689690.0\n"},
+		{"json lines", `{"timestamp": "2025-01-01T00:00:00Z"}
+{"timestamp": 1735689660, "key": "a"}
+{"timestamp": "2025-01-01T01:01:30+01:00"}
+`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ReadSigningLog(strings.NewReader(tc.log))
+			if err != nil {
+				t.Fatalf("ReadSigningLog() = %v", err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("ReadSigningLog() = %v, want %v", got, want)
+			}
+			for i := range got {
+				if !got[i].Equal(want[i]) {
+					t.Errorf("ReadSigningLog() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+
+	for _, log := range []string{"time\nyesterday\n", `{"key": "a"}`, "{"} {
+		if _, err := ReadSigningLog(strings.NewReader(log)); err == nil {
+			t.Errorf("ReadSigningLog(%q) = nil, want error", log)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	p, err := slhdsa.ParameterSetByName("rls128cs1")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	// One signature a minute for a day
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := range 24 * 60 {
+		times = append(times, start.Add(time.Duration(i)*time.Minute))
+	}
+	rate, err := FitRate(times)
+	if err != nil {
+		t.Fatalf("FitRate() = %v", err)
+	}
+	if math.Abs(rate-1.0/60) > 1e-9 {
+		t.Errorf("FitRate() = %v, want %v", rate, 1.0/60)
+	}
+	if _, err := FitRate(times[:1]); err == nil {
+		t.Errorf("FitRate() = nil, want error")
+	}
+
+	forecasts := Project(p, times, rate, []int{p.TargetSecurityLevel, p.OveruseSecurityLevel})
+	for _, forecast := range forecasts {
+		// The forecast agrees with the plan for a key signing at the same rate since the start
+		milestone := Plan(p, Schedule{{Rate: rate}}, []int{forecast.SecurityLevel})[0]
+		// (a time.Duration cannot hold the hundreds of years until the overuse level)
+		years := float64(forecast.Date.Unix()-start.Unix()) / SecondsPerYear
+		if forecast.Never || math.Abs(years-milestone.Years) > 0.001 {
+			t.Errorf("%v: Project() = %+v (%v years), want %v years", forecast.SecurityLevel, forecast, years, milestone.Years)
+		}
+		if want := float64(len(times)) / math.Floor(math.Exp2(forecast.Signatures)); forecast.Used != want {
+			t.Errorf("%v: Used = %v, want %v", forecast.SecurityLevel, forecast.Used, want)
+		}
+	}
+
+	if got := Project(p, times, 0, []int{p.TargetSecurityLevel}); !got[0].Never {
+		t.Errorf("Project() = %+v, want never", got)
+	}
+
+	// A key that has made no signatures starts signing now
+	before := time.Now()
+	forecast := Project(p, nil, rate, []int{p.TargetSecurityLevel})[0]
+	milestone := Plan(p, Schedule{{Rate: rate}}, []int{p.TargetSecurityLevel})[0]
+	if years := float64(forecast.Date.Unix()-before.Unix()) / SecondsPerYear; forecast.Never || forecast.Used != 0 || math.Abs(years-milestone.Years) > 0.001 {
+		t.Errorf("Project() with no signatures = %+v (%v years), want %v years", forecast, years, milestone.Years)
+	}
+}
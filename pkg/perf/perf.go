@@ -0,0 +1,175 @@
+// Package perf converts the costs of SLH-DSA parameter sets into estimated wall-clock time, using
+// the measured speed of the hash primitives on a machine.
+package perf
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// Profile is the speed of each hash primitive on a machine.
+type Profile struct {
+	// The name of the profile, by which it can be selected from a Registry
+	Name string `json:"name"`
+	// A description of the machine the profile was measured on
+	Machine string `json:"machine"`
+	// The time in seconds of a single call to each primitive on one core
+	Seconds map[slhdsa.Primitive]float64 `json:"seconds"`
+}
+
+// Time returns the estimated time in seconds for the calls to the hash functions of the parameter
+// set, with its hash family.
+func (prof Profile) Time(p slhdsa.ParameterSet, calls slhdsa.HashCalls) (float64, error) {
+	var seconds float64
+	for primitive, count := range p.PrimitiveCounts(calls) {
+		if count == 0 {
+			continue
+		}
+		perCall, ok := prof.Seconds[primitive]
+		if !ok {
+			return 0, fmt.Errorf("profile has no timing for primitive %q", primitive)
+		}
+		seconds += float64(count) * perCall
+	}
+	return seconds, nil
+}
+
+// SignatureSeconds returns the estimated time in seconds to produce a signature.
+func (prof Profile) SignatureSeconds(p slhdsa.ParameterSet) (float64, error) {
+	return prof.Time(p, p.SignatureHashCalls())
+}
+
+// CachedSignatureSeconds returns the estimated time in seconds to produce a signature if the
+// hypertree is cached.
+func (prof Profile) CachedSignatureSeconds(p slhdsa.ParameterSet) (float64, error) {
+	return prof.Time(p, p.CachedSignatureHashCalls())
+}
+
+// VerifySeconds returns the estimated time in seconds to verify a signature.
+func (prof Profile) VerifySeconds(p slhdsa.ParameterSet) (float64, error) {
+	return prof.Time(p, p.VerifyHashCalls())
+}
+
+// VerifySecondsAt returns the estimated time in seconds to verify a signature for the given percentile
+// of messages (see slhdsa.ParameterSet.VerifyHashCallsAt).
+func (prof Profile) VerifySecondsAt(p slhdsa.ParameterSet, percentile float64) (float64, error) {
+	return prof.Time(p, p.VerifyHashCallsAt(percentile))
+}
+
+// parallelTime returns an upper bound on the time in seconds for the calls, with the given chains of
+// dependent calls, on the given number of cores, by Brent's theorem (see slhdsa.ParallelCost).
+func (prof Profile) parallelTime(p slhdsa.ParameterSet, calls slhdsa.HashCalls, paths []slhdsa.HashCalls, cores int) (float64, error) {
+	work, err := prof.Time(p, calls)
+	if err != nil || cores <= 1 {
+		return work, err
+	}
+	var span float64
+	for _, path := range paths {
+		seconds, err := prof.Time(p, path)
+		if err != nil {
+			return 0, err
+		}
+		span = max(span, seconds)
+	}
+	return span + (work-span)/float64(cores), nil
+}
+
+// ParallelSignatureSeconds returns the estimated time in seconds to produce a signature on the given
+// number of cores.
+func (prof Profile) ParallelSignatureSeconds(p slhdsa.ParameterSet, cores int) (float64, error) {
+	return prof.parallelTime(p, p.SignatureHashCalls(), p.SignaturePaths(), cores)
+}
+
+// ParallelCachedSignatureSeconds returns the estimated time in seconds to produce a signature if the
+// hypertree is cached on the given number of cores.
+func (prof Profile) ParallelCachedSignatureSeconds(p slhdsa.ParameterSet, cores int) (float64, error) {
+	return prof.parallelTime(p, p.CachedSignatureHashCalls(), p.CachedSignaturePaths(), cores)
+}
+
+// CacheSignatureSeconds returns the estimated time in seconds to produce a signature with the given
+// hypertree cache on the given number of cores.
+func (prof Profile) CacheSignatureSeconds(p slhdsa.ParameterSet, cache slhdsa.HypertreeCache, cores int) (float64, error) {
+	return prof.parallelTime(p, cache.SignatureHashCalls(p), cache.SignaturePaths(p), cores)
+}
+
+// Load reads a profile saved by Save.
+func Load(path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	var prof Profile
+	if err := json.Unmarshal(data, &prof); err != nil {
+		return Profile{}, fmt.Errorf("could not parse profile %v: %v", path, err)
+	}
+	return prof, nil
+}
+
+// Save writes the profile to a file.
+func (prof Profile) Save(path string) error {
+	data, err := json.MarshalIndent(prof, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// The inputs to the benchmarks, shaped like a call to F after the PK.seed block: a compressed address
+// and an n-byte value for SHA-256, a compressed address and two 32-byte values (H at n = 32) for
+// SHA-512, and PK.seed, an address and a 32-byte value for SHAKE256. Each takes a single call to the
+// primitive.
+var (
+	sha256Input = make([]byte, 22+16)
+	sha512Input = make([]byte, 22+64)
+	shakeInput  = make([]byte, 32+32+32)
+)
+
+// Where the benchmarks store their results, so that the compiler cannot elide them
+var sink byte
+
+// The functions measured for each primitive
+var benchmarks = map[slhdsa.Primitive]func(){
+	slhdsa.PrimitiveSHA256: func() { sink ^= sha256.Sum256(sha256Input)[0] },
+	slhdsa.PrimitiveSHA512: func() { sink ^= sha512.Sum512(sha512Input)[0] },
+	slhdsa.PrimitiveKeccak: func() { sink ^= sha3.SumSHAKE256(shakeInput, 32)[0] },
+}
+
+// Calibrate measures the speed of each primitive on this machine, spending about the given duration
+// on each. An abstract hash is timed as a SHA-256 compression.
+func Calibrate(duration time.Duration) Profile {
+	prof := Profile{
+		Name:    "calibrated",
+		Machine: fmt.Sprintf("%s/%s, %d CPUs", runtime.GOOS, runtime.GOARCH, runtime.NumCPU()),
+		Seconds: make(map[slhdsa.Primitive]float64),
+	}
+	for primitive, benchmark := range benchmarks {
+		prof.Seconds[primitive] = measure(benchmark, duration)
+	}
+	prof.Seconds[slhdsa.PrimitiveHash] = prof.Seconds[slhdsa.PrimitiveSHA256]
+	return prof
+}
+
+// measure returns the average time in seconds of a call to f, calling it repeatedly for about the
+// given duration.
+func measure(f func(), duration time.Duration) float64 {
+	var calls int64
+	start := time.Now()
+	// Check the time only every batch of calls, doubling the batch until it takes a while
+	for batch := int64(1); ; batch = min(2*batch, 1<<20) {
+		for range batch {
+			f()
+		}
+		calls += batch
+		if elapsed := time.Since(start); elapsed >= duration {
+			return elapsed.Seconds() / float64(calls)
+		}
+	}
+}
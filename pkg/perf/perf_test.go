@@ -0,0 +1,199 @@
+package perf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+func TestProfile(t *testing.T) {
+	prof := Calibrate(10 * time.Millisecond)
+	for _, primitive := range []slhdsa.Primitive{slhdsa.PrimitiveHash, slhdsa.PrimitiveSHA256, slhdsa.PrimitiveSHA512, slhdsa.PrimitiveKeccak} {
+		if prof.Seconds[primitive] <= 0 {
+			t.Errorf("Calibrate() has no timing for %v: %+v", primitive, prof)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := prof.Save(path); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if loaded.Machine != prof.Machine || len(loaded.Seconds) != len(prof.Seconds) {
+		t.Errorf("Load() = %+v, want %+v", loaded, prof)
+	}
+}
+
+func TestTime(t *testing.T) {
+	prof := Profile{Seconds: map[slhdsa.Primitive]float64{
+		slhdsa.PrimitiveHash:   1e-6,
+		slhdsa.PrimitiveSHA256: 1e-6,
+		slhdsa.PrimitiveSHA512: 2e-6,
+	}}
+	p, err := slhdsa.ParameterSetByName("SLH-DSA-SHA2-256s")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+
+	// Abstract hashes take a microsecond each
+	seconds, err := prof.SignatureSeconds(p)
+	if err != nil {
+		t.Fatalf("SignatureSeconds() = %v", err)
+	}
+	if want := float64(p.SignatureHashes()) * 1e-6; seconds != want {
+		t.Errorf("SignatureSeconds() = %v, want %v", seconds, want)
+	}
+
+	// With SHA2, some of the compressions are SHA-512, which takes longer
+	p.HashFamily = slhdsa.SHA2{}
+	seconds, err = prof.VerifySeconds(p)
+	if err != nil {
+		t.Fatalf("VerifySeconds() = %v", err)
+	}
+	if min := float64(p.VerifyCost()) * 1e-6; seconds <= min || seconds >= 2*min {
+		t.Errorf("VerifySeconds() = %v, want between %v and %v", seconds, min, 2*min)
+	}
+
+	// The worst case takes longer than the median
+	median, err := prof.VerifySecondsAt(p, 50)
+	if err != nil {
+		t.Fatalf("VerifySecondsAt() = %v", err)
+	}
+	if worst, err := prof.VerifySecondsAt(p, 100); err != nil || worst <= median {
+		t.Errorf("VerifySecondsAt(100) = %v, %v, want more than %v", worst, err, median)
+	}
+
+	// On many cores, signing takes about as long as its critical path
+	seconds, err = prof.ParallelSignatureSeconds(p, 1<<30)
+	if err != nil {
+		t.Fatalf("ParallelSignatureSeconds() = %v", err)
+	}
+	if min := float64(p.SignatureSpan()) * 1e-6; seconds <= min || seconds >= 2*min {
+		t.Errorf("ParallelSignatureSeconds() = %v, want between %v and %v", seconds, min, 2*min)
+	}
+
+	// There is no timing for SHAKE
+	p.HashFamily = slhdsa.SHAKE{}
+	if _, err := prof.CachedSignatureSeconds(p); err == nil {
+		t.Errorf("CachedSignatureSeconds() = nil, want error")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := BuiltinProfiles()
+	for _, name := range r.Names() {
+		prof, err := r.Lookup(name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) = %v", name, err)
+		}
+		// Every built-in profile can time every hash family
+		for _, family := range slhdsa.HashFamilyNames() {
+			p, err := slhdsa.ParameterSetByName("SLH-DSA-SHA2-192f")
+			if err != nil {
+				t.Fatalf("ParameterSetByName() = %v", err)
+			}
+			if p.HashFamily, err = slhdsa.HashFamilyByName(family); err != nil {
+				t.Fatalf("HashFamilyByName() = %v", err)
+			}
+			if _, err := prof.SignatureSeconds(p); err != nil {
+				t.Errorf("%v: SignatureSeconds() with %v = %v", name, family, err)
+			}
+		}
+	}
+
+	dir := t.TempDir()
+	single := filepath.Join(dir, "single.json")
+	prof := Calibrate(time.Millisecond)
+	if err := prof.Save(single); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	list := filepath.Join(dir, "list.json")
+	if err := os.WriteFile(list, []byte(`[{"name": "My-HSM", "seconds": {"hash": 2e-6}}, {"name": "typical-hsm", "machine": "mine"}]`), 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	for _, path := range []string{single, list} {
+		if err := r.Load(path); err != nil {
+			t.Fatalf("Load(%v) = %v", path, err)
+		}
+	}
+	if got, err := r.Lookup("calibrated"); err != nil || got.Machine != prof.Machine {
+		t.Errorf("Lookup(calibrated) = %+v, %v", got, err)
+	}
+	if got, err := r.Lookup("my-hsm"); err != nil || got.Seconds[slhdsa.PrimitiveHash] != 2e-6 {
+		t.Errorf("Lookup(my-hsm) = %+v, %v", got, err)
+	}
+	// Loaded profiles replace built-in ones
+	if got, err := r.Lookup("typical-hsm"); err != nil || got.Machine != "mine" {
+		t.Errorf("Lookup(typical-hsm) = %+v, %v", got, err)
+	}
+
+	if _, err := r.Lookup("abacus"); err == nil {
+		t.Errorf("Lookup() = nil, want error")
+	}
+	unnamed := filepath.Join(dir, "unnamed.json")
+	if err := os.WriteFile(unnamed, []byte(`{"machine": "mine"}`), 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := r.Load(unnamed); err == nil {
+		t.Errorf("Load() = nil, want error")
+	}
+}
+
+func TestDevices(t *testing.T) {
+	dir := t.TempDir()
+	calibration := filepath.Join(dir, "calibration.json")
+	if err := Calibrate(time.Millisecond).Save(calibration); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+	profiles := filepath.Join(dir, "profiles.json")
+	if err := os.WriteFile(profiles, []byte(`{"name": "my-hsm", "seconds": {"hash": 2e-6}}`), 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	for _, tc := range []struct {
+		name                     string
+		calibration, profiles    string
+		signerName, verifierName string
+		wantSigner, wantVerifier string
+		wantErr                  bool
+	}{
+		{name: "none"},
+		{name: "calibration", calibration: calibration, wantSigner: "calibrated", wantVerifier: "calibrated"},
+		{name: "override signer", calibration: calibration, signerName: "typical-hsm", wantSigner: "typical-hsm", wantVerifier: "calibrated"},
+		{name: "loaded verifier", profiles: profiles, verifierName: "My-HSM", wantVerifier: "my-hsm"},
+		{name: "unknown", signerName: "my-hsm", wantErr: true},
+		{name: "missing calibration", calibration: filepath.Join(dir, "missing.json"), wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, verifier, err := BuiltinProfiles().Devices(tc.calibration, tc.profiles, tc.signerName, tc.verifierName)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Devices() = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Devices() = %v", err)
+			}
+			for _, side := range []struct {
+				side string
+				got  *Profile
+				want string
+			}{{"signer", signer, tc.wantSigner}, {"verifier", verifier, tc.wantVerifier}} {
+				name := ""
+				if side.got != nil {
+					name = side.got.Name
+				}
+				if name != side.want {
+					t.Errorf("Devices() %v = %q, want %q", side.side, name, side.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package perf
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// The built-in profiles. These are rough estimates for typical devices, meant as starting points:
+// calibrate or measure the actual device for real decisions.
+var builtinProfiles = []Profile{
+	{
+		Name:    "typical-hsm",
+		Machine: "an HSM hashing about 1 million SHA-256 blocks per second",
+		Seconds: map[slhdsa.Primitive]float64{
+			slhdsa.PrimitiveHash:   1e-6,
+			slhdsa.PrimitiveSHA256: 1e-6,
+			slhdsa.PrimitiveSHA512: 1.5e-6,
+			slhdsa.PrimitiveKeccak: 2e-6,
+		},
+	},
+	{
+		Name:    "cortex-m4",
+		Machine: "a 168 MHz Cortex-M4 microcontroller with software hashing",
+		Seconds: map[slhdsa.Primitive]float64{
+			slhdsa.PrimitiveHash:   15e-6,
+			slhdsa.PrimitiveSHA256: 15e-6,
+			slhdsa.PrimitiveSHA512: 76e-6,
+			slhdsa.PrimitiveKeccak: 77e-6,
+		},
+	},
+	{
+		Name:    "x86-64",
+		Machine: "an 8-core 3 GHz x86-64 server with the SHA extensions",
+		Seconds: map[slhdsa.Primitive]float64{
+			slhdsa.PrimitiveHash:   70e-9,
+			slhdsa.PrimitiveSHA256: 70e-9,
+			slhdsa.PrimitiveSHA512: 230e-9,
+			slhdsa.PrimitiveKeccak: 330e-9,
+		},
+	},
+}
+
+// Registry is a set of profiles, keyed by name.
+type Registry map[string]Profile
+
+// BuiltinProfiles returns a registry of the built-in profiles, to which more can be added.
+func BuiltinProfiles() Registry {
+	r := make(Registry)
+	for _, prof := range builtinProfiles {
+		r[prof.Name] = prof
+	}
+	return r
+}
+
+// Load adds the profiles in a JSON file, which holds either a single profile (such as one saved by
+// Save) or a list of them, replacing any existing profiles with the same names.
+func (r Registry) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var profiles []Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		var prof Profile
+		if err := json.Unmarshal(data, &prof); err != nil {
+			return fmt.Errorf("could not parse profiles %v: %v", path, err)
+		}
+		profiles = []Profile{prof}
+	}
+	for _, prof := range profiles {
+		if prof.Name == "" {
+			return fmt.Errorf("profile in %v has no name", path)
+		}
+		r[strings.ToLower(prof.Name)] = prof
+	}
+	return nil
+}
+
+// Lookup returns the profile with the given name.
+func (r Registry) Lookup(name string) (Profile, error) {
+	prof, ok := r[strings.ToLower(name)]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q (known profiles: %v)", name, strings.Join(r.Names(), ", "))
+	}
+	return prof, nil
+}
+
+// Names returns the names of the profiles, in sorted order.
+func (r Registry) Names() []string {
+	return slices.Sorted(maps.Keys(r))
+}
+
+// Devices returns the profiles of the signing and verifying devices, either of which is nil if no
+// profile is given for that side. The profile saved by Save at calibration (if not empty) is used for
+// both sides, unless overridden by the profile named by signer or verifier. The profiles in the file at
+// profiles (if not empty) are added to the registry first, so either name may refer to one of them.
+func (r Registry) Devices(calibration, profiles, signer, verifier string) (signerProf, verifierProf *Profile, err error) {
+	if calibration != "" {
+		prof, err := Load(calibration)
+		if err != nil {
+			return nil, nil, err
+		}
+		signerProf, verifierProf = &prof, &prof
+	}
+	if profiles != "" {
+		if err := r.Load(profiles); err != nil {
+			return nil, nil, err
+		}
+	}
+	if signer != "" {
+		prof, err := r.Lookup(signer)
+		if err != nil {
+			return nil, nil, err
+		}
+		signerProf = &prof
+	}
+	if verifier != "" {
+		prof, err := r.Lookup(verifier)
+		if err != nil {
+			return nil, nil, err
+		}
+		verifierProf = &prof
+	}
+	return signerProf, verifierProf, nil
+}
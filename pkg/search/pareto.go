@@ -0,0 +1,120 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// Objectives are the properties of a parameter set that the Pareto frontier is computed over.
+type Objectives struct {
+	// The size in bytes of each signature (smaller is better)
+	SignatureSize int
+	// The cost of producing a signature, in the units of the hash family, with Parameters.SignCores hash units (smaller is better)
+	SignatureHashes int64
+	// The cost of producing a signature with Parameters.Cache, in the units of the hash family, with Parameters.SignCores hash units (smaller is better)
+	CachedSignatureHashes int64
+	// The cost of verifying a signature, in the units of the hash family, as counted by Parameters.VerifyCost (smaller is better)
+	VerifyHashes int64
+	// The log_2 of the number of signatures that can be performed while retaining the overuse
+	// security level, or 0 if there is no overuse security level (larger is better)
+	OveruseSignatures float64
+}
+
+// Dominates reports whether o is at least as good as other in every objective, and strictly better
+// in at least one.
+func (o Objectives) Dominates(other Objectives) bool {
+	if o.SignatureSize > other.SignatureSize ||
+		o.SignatureHashes > other.SignatureHashes ||
+		o.CachedSignatureHashes > other.CachedSignatureHashes ||
+		o.VerifyHashes > other.VerifyHashes ||
+		o.OveruseSignatures < other.OveruseSignatures {
+		return false
+	}
+	return o != other
+}
+
+// Objectives returns the objectives of the candidate.
+func (p *Parameters) Objectives(candidate *slhdsa.ParameterSet) Objectives {
+	objectives := Objectives{
+		SignatureSize:         candidate.SignatureSize(),
+		SignatureHashes:       candidate.ParallelSignatureCost(p.SignCores),
+		CachedSignatureHashes: p.cachedSignatureCost(candidate),
+		VerifyHashes:          p.verifyCost(candidate),
+	}
+	if p.OveruseSecurityLevel > 0 {
+		objectives.OveruseSignatures = candidate.SignaturesAtLevel(p.OveruseSecurityLevel)
+	}
+	return objectives
+}
+
+// frontier keeps the candidates seen so far that are not dominated by any other candidate.
+type frontier struct {
+	params     *Parameters
+	sets       []slhdsa.ParameterSet
+	objectives []Objectives
+}
+
+func newFrontier(params *Parameters) *frontier {
+	return &frontier{params: params}
+}
+
+// insert adds the candidate if no other candidate dominates it, removing any candidates that it
+// dominates.
+func (f *frontier) insert(candidate *slhdsa.ParameterSet) {
+	objectives := f.params.Objectives(candidate)
+	for _, other := range f.objectives {
+		if other.Dominates(objectives) {
+			return
+		}
+	}
+	sets, all := f.sets[:0], f.objectives[:0]
+	for i, other := range f.objectives {
+		if !objectives.Dominates(other) {
+			sets = append(sets, f.sets[i])
+			all = append(all, other)
+		}
+	}
+	f.sets = append(sets, *candidate)
+	f.objectives = append(all, objectives)
+}
+
+func (f *frontier) result() []slhdsa.ParameterSet {
+	return f.sets
+}
+
+// Pareto performs the parameter set space search and returns every candidate on the Pareto frontier
+// of the search's Objectives.
+func Pareto(params *Parameters) []slhdsa.ParameterSet {
+	result, _ := ParetoContext(context.Background(), params)
+	return result
+}
+
+// ParetoContext performs the parameter set space search and returns every candidate on the Pareto
+// frontier of the search's Objectives. If the context is cancelled or its deadline passes before
+// the search is complete, the frontier of the candidates found so far is returned along with the
+// context's error.
+//
+// The candidates are ordered using Compare, or by signature size if Compare is nil.
+// CandidateCount is ignored.
+func ParetoContext(ctx context.Context, params *Parameters) ([]slhdsa.ParameterSet, error) {
+	result, err := run(ctx, params, func() collector { return newFrontier(params) })
+	slices.SortFunc(result, func(a, b slhdsa.ParameterSet) int {
+		if params.Compare != nil {
+			switch {
+			case params.better(&a, &b):
+				return -1
+			case params.better(&b, &a):
+				return 1
+			}
+			return compareShapes(&a, &b)
+		}
+		return cmp.Or(
+			cmp.Compare(a.SignatureSize(), b.SignatureSize()),
+			compareShapes(&a, &b),
+		)
+	})
+	return result, err
+}
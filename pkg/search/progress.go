@@ -0,0 +1,100 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// Progress describes how far a search has got.
+type Progress struct {
+	// The number of candidates in the search space
+	Total int64
+	// The number of candidates that have been evaluated (including those that were pruned)
+	Evaluated int64
+	// The number of candidates found so far that meet all of the search constraints
+	Feasible int64
+	// The best candidate found so far (nil if there is none yet)
+	Best *slhdsa.ParameterSet
+}
+
+// tracker keeps track of the progress of a search across all of the workers.
+type tracker struct {
+	params    *Parameters
+	total     int64
+	evaluated atomic.Int64
+	feasible  atomic.Int64
+
+	mu   sync.Mutex
+	best *slhdsa.ParameterSet
+}
+
+func newTracker(params *Parameters) *tracker {
+	return &tracker{
+		params: params,
+		total:  int64(len(params.HPrime) * len(params.D) * len(params.LgW) * len(params.K) * len(params.T)),
+	}
+}
+
+// batchDone records that a worker has finished a batch, of which it visited the given number of
+// candidates (fewer than the whole batch if the search was cancelled), along with the best candidate
+// it has seen.
+func (t *tracker) batchDone(visited, feasible int64, best *slhdsa.ParameterSet) {
+	t.evaluated.Add(visited)
+	t.feasible.Add(feasible)
+	if best == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.best == nil || t.params.better(best, t.best) {
+		copied := *best
+		t.best = &copied
+	}
+}
+
+func (t *tracker) progress() Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	progress := Progress{
+		Total:     t.total,
+		Evaluated: t.evaluated.Load(),
+		Feasible:  t.feasible.Load(),
+	}
+	if t.best != nil {
+		best := *t.best
+		progress.Best = &best
+	}
+	return progress
+}
+
+// report calls the progress callback (if any) periodically until done is closed, and once more
+// after that.
+func (t *tracker) report(ctx context.Context, done <-chan struct{}) {
+	if t.params.Progress == nil {
+		return
+	}
+	interval := t.params.ProgressInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.params.Progress(t.progress())
+		case <-ctx.Done():
+			<-done
+			t.params.Progress(t.progress())
+			return
+		case <-done:
+			t.params.Progress(t.progress())
+			return
+		}
+	}
+}
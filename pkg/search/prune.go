@@ -0,0 +1,141 @@
+package search
+
+import (
+	"context"
+	"iter"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// space is the search space of a single search, along with the state used to prune it.
+//
+// Pruning relies on the fact that the signature size and all of the costs of a parameter set
+// (including the time to sign with parallel hash units) increase with both K and T, that the size of
+// the hypertree cache does not depend on them, and that the security level of a parameter set
+// depends only on K, T and the hypertree height, increasing with each of them.
+type space struct {
+	params *Parameters
+	// The sorted values of K and T
+	ks, ts []int
+	// The distinct hypertree heights in the search space, in ascending order
+	heights []height
+
+	mu sync.Mutex
+	// Memoized minimum hypertree heights, keyed by {K, T}
+	minHeights map[[2]int]*minHeight
+}
+
+// height is a hypertree height, along with a choice of HPrime and D that produces it.
+type height struct {
+	h      int
+	hPrime int
+	d      int
+}
+
+// minHeight is the smallest hypertree height in the search space at which a given K and T meet the
+// security requirements (math.MaxInt if there is none).
+type minHeight struct {
+	once sync.Once
+	h    int
+}
+
+func newSpace(p *Parameters) *space {
+	s := &space{
+		params:     p,
+		ks:         slices.Sorted(slices.Values(p.K)),
+		ts:         slices.Sorted(slices.Values(p.T)),
+		minHeights: make(map[[2]int]*minHeight),
+	}
+	for _, hPrime := range p.HPrime {
+		for _, d := range p.D {
+			s.heights = append(s.heights, height{h: hPrime * d, hPrime: hPrime, d: d})
+		}
+	}
+	slices.SortFunc(s.heights, func(a, b height) int { return a.h - b.h })
+	s.heights = slices.CompactFunc(s.heights, func(a, b height) bool { return a.h == b.h })
+	return s
+}
+
+// minHeight returns the smallest hypertree height in the search space at which the given K and T
+// meet the security requirements, computing it if needed.
+func (s *space) minHeight(k, t int) int {
+	s.mu.Lock()
+	m, ok := s.minHeights[[2]int{k, t}]
+	if !ok {
+		m = &minHeight{}
+		s.minHeights[[2]int{k, t}] = m
+	}
+	s.mu.Unlock()
+
+	m.once.Do(func() {
+		i := sort.Search(len(s.heights), func(i int) bool {
+			return s.params.secure(s.params.candidate(batch{hPrime: s.heights[i].hPrime, d: s.heights[i].d}, k, t))
+		})
+		m.h = math.MaxInt
+		if i < len(s.heights) {
+			m.h = s.heights[i].h
+		}
+	})
+	return m.h
+}
+
+// candidates yields every candidate in the batch that meets the search constraints, stopping early
+// if the context is done. It adds the number of candidates it has evaluated or pruned to visited.
+//
+// Unless the search is exhaustive, the batch is pruned:
+//   - Once the smallest T exceeds a maximum for some K, every larger K does too.
+//   - Once some T exceeds a maximum for a given K, every larger T does too.
+//   - Once some T meets the security requirements for a given K, every larger T does too.
+//   - Once some hypertree height meets the security requirements for a given K and T, every larger
+//     height does too, so each K and T only needs to be checked at a handful of heights.
+func (s *space) candidates(ctx context.Context, b batch, visited *int64) iter.Seq[*slhdsa.ParameterSet] {
+	p := s.params
+	return func(yield func(*slhdsa.ParameterSet) bool) {
+		if p.Exhaustive {
+			for _, k := range p.K {
+				if ctx.Err() != nil {
+					return
+				}
+				*visited += int64(len(p.T))
+				for _, t := range p.T {
+					candidate := p.candidate(b, k, t)
+					if p.withinBounds(candidate) && p.accept(candidate) && p.secure(candidate) {
+						if !yield(candidate) {
+							return
+						}
+					}
+				}
+			}
+			return
+		}
+
+		h := b.hPrime * b.d
+		for ki, k := range s.ks {
+			if ctx.Err() != nil {
+				return
+			}
+			// Find the range of T values that are within the maximums
+			hi := sort.Search(len(s.ts), func(i int) bool { return !p.withinBounds(p.candidate(b, k, s.ts[i])) })
+			if hi == 0 {
+				// This and every larger K are pruned
+				*visited += int64((len(s.ks) - ki) * len(s.ts))
+				break
+			}
+			*visited += int64(len(s.ts))
+			// Find the smallest T value that is secure enough
+			lo := sort.Search(hi, func(i int) bool { return h >= s.minHeight(k, s.ts[i]) })
+			for _, t := range s.ts[lo:hi] {
+				candidate := p.candidate(b, k, t)
+				if p.accept(candidate) {
+					if !yield(candidate) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
@@ -2,11 +2,14 @@
 package search
 
 import (
+	"cmp"
+	"context"
 	"iter"
 	"math"
-	"slices"
-	"sort"
+	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
 )
@@ -21,6 +24,12 @@ type Parameters struct {
 	OveruseSecurityLevel int
 	// The minimum number of signatures this parameter set must be able to support at overuse security level (ignored if <= 0 or if OveruseSecurityLevel <= 0)
 	MinOveruseSignatures float64
+	// Additional overuse security levels, each with a minimum number of signatures this parameter set must be able to support at that level
+	OveruseTiers []OveruseTier
+	// A curve the security level must stay above, checked at each of its breakpoints (ignored if empty)
+	SecurityFloor slhdsa.SecurityFloor
+	// The number of keys that will use this parameter set, each of which makes the minimum numbers of signatures (ignored if <= 1)
+	NumKeys int
 	// Acceptable XMSS key heights
 	HPrime []int
 	// Acceptable number of layers of one-time signatures and Merkle trees within the hypertree
@@ -31,42 +40,79 @@ type Parameters struct {
 	K []int
 	// Acceptable values for 2^a = t, the number of private values within each FORS set
 	T []int
+	// The analysis used to compute the security level (defaults to slhdsa.DefaultSecurityModel if nil)
+	SecurityModel slhdsa.SecurityModel
+	// The instantiation of the hash functions, which determines the units of the costs below (defaults to slhdsa.DefaultHashFamily if nil)
+	HashFamily slhdsa.HashFamily
+	// The length in bytes of the messages that are signed (defaults to slhdsa.DefaultMessageBytes if <= 0)
+	MessageBytes int
+	// Whether messages are pre-hashed (HashSLH-DSA)
+	PreHash bool
+	// Which parts of the hypertree are cached for the cached signature costs below (the whole hypertree if nil)
+	Cache *slhdsa.HypertreeCache
+	// The number of parallel hash units that sign, so that the signature costs below are the time to sign with all of them (ignored if <= 1)
+	SignCores int
 
-	// A function that determines whether a given signature size is acceptable
+	// The maximum signature size (ignored if <= 0)
+	MaxSignatureSize int
+	// The maximum signature cost (ignored if <= 0)
+	MaxSignatureHashes int64
+	// The maximum signature cost when the hypertree is cached (ignored if <= 0)
+	MaxCachedSignatureHashes int64
+	// The maximum verification cost (ignored if <= 0)
+	MaxVerifyHashes int64
+	// The cost of verifying a candidate, for the verification costs below, which must increase with K and T (defaults to the average, ParameterSet.VerifyCost, if nil)
+	VerifyCost func(*slhdsa.ParameterSet) int64
+	// The maximum size in bytes of the hypertree cache (ignored if <= 0 or if Cache is nil)
+	MaxCacheBytes float64
+	// Disables pruning of the search space, so that every candidate is evaluated
+	Exhaustive bool
+
+	// A function that determines whether a given signature size is acceptable (ignored if nil)
 	SignatureSize func(int) bool
-	// A function that determines whether a given signature cost is acceptable
+	// A function that determines whether a given signature cost is acceptable (ignored if nil)
 	SignatureHashes func(int64) bool
-	// A function that determines whether a given signature cost is acceptable (when 1 layer of the hypertree is cached)
+	// A function that determines whether a given signature cost is acceptable (with Cache) (ignored if nil)
 	CachedSignatureHashes func(int64) bool
-	// A function that determines whether a given verification cost is acceptable
+	// A function that determines whether a given verification cost is acceptable (ignored if nil)
 	VerifyHashes func(int64) bool
+	// A function that determines whether a candidate is acceptable, for constraints not covered above (ignored if nil)
+	Filter func(*slhdsa.ParameterSet) bool
 	// A function that compares two parameter sets, returns true if p1 is "better" than p2
 	Compare func(p1, p2 *slhdsa.ParameterSet) bool
 	// Max number of candidate parameter sets to print
 	CandidateCount int
+	// Number of worker goroutines evaluating candidates (defaults to GOMAXPROCS if <= 0)
+	Workers int
+	// A function that is called periodically with the progress of the search (ignored if nil)
+	Progress func(Progress)
+	// How often to call Progress (defaults to 1 second if <= 0)
+	ProgressInterval time.Duration
+}
+
+// OveruseTier is a security level that must be retained up to a number of signatures.
+type OveruseTier struct {
+	// The security level in bits
+	SecurityLevel int
+	// The minimum number of signatures this parameter set must be able to support at the security level
+	MinSignatures float64
+}
+
+// batch is a unit of work handed to a search worker: every combination of K and T
+// for a single choice of HPrime, D and LgW.
+type batch struct {
+	hPrime int
+	d      int
+	lgW    int
 }
 
-func (p *Parameters) candidates() iter.Seq[*slhdsa.ParameterSet] {
-	return func(yield func(*slhdsa.ParameterSet) bool) {
+func (p *Parameters) batches() iter.Seq[batch] {
+	return func(yield func(batch) bool) {
 		for _, hPrime := range p.HPrime {
 			for _, d := range p.D {
 				for _, lgW := range p.LgW {
-					for _, k := range p.K {
-						for _, t := range p.T {
-							candidate := slhdsa.ParameterSet{
-								TargetSecurityLevel:  p.TargetSecurityLevel,
-								OveruseSecurityLevel: p.OveruseSecurityLevel,
-								HPrime:               hPrime,
-								D:                    d,
-								LgW:                  lgW,
-								K:                    k,
-								T:                    t,
-							}
-							// Yield the candidate
-							if !yield(&candidate) {
-								return
-							}
-						}
+					if !yield(batch{hPrime: hPrime, d: d, lgW: lgW}) {
+						return
 					}
 				}
 			}
@@ -74,80 +120,226 @@ func (p *Parameters) candidates() iter.Seq[*slhdsa.ParameterSet] {
 	}
 }
 
-// Search performs the parameter set space search and returns the top `CandidateCount` candidates.
-func Search(params *Parameters) []slhdsa.ParameterSet {
-	result := make([]slhdsa.ParameterSet, 0, params.CandidateCount+1)
-	candidateQueue := make(chan *slhdsa.ParameterSet)
-	var wg1, wg2 sync.WaitGroup
+func (p *Parameters) candidate(b batch, k, t int) *slhdsa.ParameterSet {
+	return &slhdsa.ParameterSet{
+		TargetSecurityLevel:  p.TargetSecurityLevel,
+		OveruseSecurityLevel: p.OveruseSecurityLevel,
+		HPrime:               b.hPrime,
+		D:                    b.d,
+		LgW:                  b.lgW,
+		K:                    k,
+		T:                    t,
+		SecurityModel:        p.SecurityModel,
+		HashFamily:           p.HashFamily,
+		MessageBytes:         p.MessageBytes,
+		PreHash:              p.PreHash,
+	}
+}
 
-	// Create a goroutine that just reads candidates out of the queue and inserts them into the result
-	wg1.Add(1)
-	go func() {
-		defer wg1.Done()
-		for {
-			candidate, ok := <-candidateQueue
-			if !ok {
-				return
-			}
+// withinBounds checks whether the candidate is within the maximum size and costs.
+func (p *Parameters) withinBounds(candidate *slhdsa.ParameterSet) bool {
+	if p.MaxSignatureSize > 0 && candidate.SignatureSize() > p.MaxSignatureSize {
+		return false
+	}
+	if p.MaxSignatureHashes > 0 && candidate.ParallelSignatureCost(p.SignCores) > p.MaxSignatureHashes {
+		return false
+	}
+	if p.MaxCachedSignatureHashes > 0 && p.cachedSignatureCost(candidate) > p.MaxCachedSignatureHashes {
+		return false
+	}
+	if p.MaxVerifyHashes > 0 && p.verifyCost(candidate) > p.MaxVerifyHashes {
+		return false
+	}
+	if p.Cache != nil && p.MaxCacheBytes > 0 && p.Cache.Bytes(*candidate) > p.MaxCacheBytes {
+		return false
+	}
+	return true
+}
 
-			// Trivial case: we have no candidates yet.
-			if len(result) == 0 {
-				result = append(result, *candidate)
-				continue
-			}
+// verifyCost returns the cost of verifying a signature.
+func (p *Parameters) verifyCost(candidate *slhdsa.ParameterSet) int64 {
+	if p.VerifyCost == nil {
+		return candidate.VerifyCost()
+	}
+	return p.VerifyCost(candidate)
+}
 
-			// We already have some candidates. Insert this one and trim off the last one.
-			i := sort.Search(len(result), func(i int) bool { return params.Compare(candidate, &result[i]) })
-			result = slices.Insert(result, i, *candidate)
-			if len(result) > params.CandidateCount {
-				result = result[:params.CandidateCount]
-			}
+// cachedSignatureCost returns the cost of producing a signature with the cache.
+func (p *Parameters) cachedSignatureCost(candidate *slhdsa.ParameterSet) int64 {
+	if p.Cache == nil {
+		return candidate.ParallelCachedSignatureCost(p.SignCores)
+	}
+	return p.Cache.ParallelSignatureCost(*candidate, p.SignCores)
+}
+
+// secure checks whether the candidate meets the security requirements.
+func (p *Parameters) secure(candidate *slhdsa.ParameterSet) bool {
+	// Check that the security level is acceptable
+	if !candidate.CheckFleetSecurityLevel(math.Log2(p.MinSignatures), p.NumKeys) {
+		return false
+	}
+
+	// Check overuse security (if applicable)
+	if p.OveruseSecurityLevel > 0 && p.MinOveruseSignatures > 0 {
+		if !candidate.CheckFleetOveruseSecurityLevel(math.Log2(p.MinOveruseSignatures), p.NumKeys) {
+			return false
 		}
-	}()
+	}
+	for _, tier := range p.OveruseTiers {
+		if candidate.FleetSecurityLevel(math.Log2(tier.MinSignatures), p.NumKeys) < float64(tier.SecurityLevel) {
+			return false
+		}
+	}
 
-	// Search the entire acceptable solution space, adding candidates to the queue
-	for candidate := range params.candidates() {
-		wg2.Add(1)
-		go func() {
-			defer wg2.Done()
+	// Check that the security level stays above the floor (if applicable)
+	if len(p.SecurityFloor) > 0 && candidate.FleetFloorMargin(p.SecurityFloor, p.NumKeys) < 0 {
+		return false
+	}
 
-			// Check that the signature size is acceptable
-			if !params.SignatureSize(candidate.SignatureSize()) {
-				return
-			}
+	return true
+}
 
-			// Check that the signature work is acceptable
-			if !params.SignatureHashes(candidate.SignatureHashes()) {
-				return
-			}
-			if !params.CachedSignatureHashes(candidate.CachedSignatureHashes()) {
-				return
-			}
+// accept checks whether the candidate meets the remaining search constraints.
+func (p *Parameters) accept(candidate *slhdsa.ParameterSet) bool {
+	// Check that the signature size is acceptable
+	if p.SignatureSize != nil && !p.SignatureSize(candidate.SignatureSize()) {
+		return false
+	}
 
-			// Check that the verify work is acceptable
-			if !params.VerifyHashes(candidate.VerifyHashes()) {
-				return
-			}
+	// Check that the signature work is acceptable
+	if p.SignatureHashes != nil && !p.SignatureHashes(candidate.ParallelSignatureCost(p.SignCores)) {
+		return false
+	}
+	if p.CachedSignatureHashes != nil && !p.CachedSignatureHashes(p.cachedSignatureCost(candidate)) {
+		return false
+	}
 
-			// Check that the security level is acceptable
-			if !candidate.CheckSecurityLevel(math.Log2(params.MinSignatures)) {
-				return
-			}
+	// Check that the verify work is acceptable
+	if p.VerifyHashes != nil && !p.VerifyHashes(p.verifyCost(candidate)) {
+		return false
+	}
 
-			// Check overuse security (if applicable)
-			if params.OveruseSecurityLevel > 0 && params.MinOveruseSignatures > 0 {
-				if !candidate.CheckOveruseSecurityLevel(math.Log2(params.MinOveruseSignatures)) {
-					return
+	if p.Filter != nil && !p.Filter(candidate) {
+		return false
+	}
+
+	return true
+}
+
+// better reports whether p1 should be ranked ahead of p2. Candidates that Compare
+// considers equivalent are ordered by their parameters, so that the result of the
+// search does not depend on the order in which the workers finish.
+func (p *Parameters) better(p1, p2 *slhdsa.ParameterSet) bool {
+	if p.Compare(p1, p2) {
+		return true
+	}
+	if p.Compare(p2, p1) {
+		return false
+	}
+	return compareShapes(p1, p2) < 0
+}
+
+// compareShapes orders parameter sets by HPrime, D, LgW, K and then T.
+func compareShapes(p1, p2 *slhdsa.ParameterSet) int {
+	return cmp.Or(
+		cmp.Compare(p1.HPrime, p2.HPrime),
+		cmp.Compare(p1.D, p2.D),
+		cmp.Compare(p1.LgW, p2.LgW),
+		cmp.Compare(p1.K, p2.K),
+		cmp.Compare(p1.T, p2.T),
+	)
+}
+
+// Search performs the parameter set space search and returns the top `CandidateCount` candidates.
+func Search(params *Parameters) []slhdsa.ParameterSet {
+	result, _ := SearchContext(context.Background(), params)
+	return result
+}
+
+// SearchContext performs the parameter set space search and returns the top `CandidateCount`
+// candidates. If the context is cancelled or its deadline passes before the search is complete, the
+// best candidates found so far are returned along with the context's error.
+func SearchContext(ctx context.Context, params *Parameters) ([]slhdsa.ParameterSet, error) {
+	return run(ctx, params, func() collector { return newTopK(params.CandidateCount, params.better) })
+}
+
+// run searches the parameter set space, collecting the acceptable candidates.
+//
+// The search space is split into batches which are evaluated by a fixed pool of workers. Each
+// worker collects the candidates it finds independently, and these are merged once all of the
+// batches have been evaluated.
+func run(ctx context.Context, params *Parameters, newCollector func() collector) ([]slhdsa.ParameterSet, error) {
+	workers := params.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	space := newSpace(params)
+	tracker := newTracker(params)
+	done := make(chan struct{})
+	var reporter sync.WaitGroup
+	reporter.Add(1)
+	go func() {
+		defer reporter.Done()
+		tracker.report(ctx, done)
+	}()
+
+	batches := make(chan batch, workers)
+	results := make([]collector, workers)
+	var wg sync.WaitGroup
+	// Set if a worker stopped partway through a batch because the context was done
+	var abandoned atomic.Bool
+
+	for i := range workers {
+		results[i] = newCollector()
+		wg.Add(1)
+		go func(found collector) {
+			defer wg.Done()
+			var best *slhdsa.ParameterSet
+			for b := range batches {
+				var visited, feasible int64
+				for candidate := range space.candidates(ctx, b, &visited) {
+					feasible++
+					found.insert(candidate)
+					if params.Compare != nil && (best == nil || params.better(candidate, best)) {
+						best = candidate
+					}
+				}
+				if visited < int64(len(params.K)*len(params.T)) {
+					abandoned.Store(true)
 				}
+				tracker.batchDone(visited, feasible, best)
 			}
+		}(results[i])
+	}
 
-			// Candidate is acceptable; enqueue it
-			candidateQueue <- candidate
-		}()
+	// Hand the entire acceptable solution space to the workers
+	var err error
+dispatch:
+	for b := range params.batches() {
+		select {
+		case batches <- b:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
 	}
-	wg2.Wait()
-	close(candidateQueue)
-	wg1.Wait()
+	close(batches)
+	wg.Wait()
+	close(done)
+	reporter.Wait()
 
-	return result
+	// Every batch was handed out, but some of them may not have been finished
+	if err == nil && abandoned.Load() {
+		err = ctx.Err()
+	}
+
+	// Merge the per-worker results
+	result := newCollector()
+	for _, found := range results {
+		for _, candidate := range found.result() {
+			result.insert(&candidate)
+		}
+	}
+	return result.result(), err
 }
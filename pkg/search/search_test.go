@@ -0,0 +1,374 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+func intsBetween(start, end int) []int {
+	result := make([]int, end-start+1)
+	for i := start; i <= end; i++ {
+		result[i-start] = i
+	}
+	return result
+}
+
+// compareLogs ranks parameter sets by a weighted sum of the logs of their signature size and
+// verification cost, as slushfind does.
+func compareLogs(sigSizeWeight, verifyCostWeight float64) func(a, b *slhdsa.ParameterSet) bool {
+	cost := func(p *slhdsa.ParameterSet) float64 {
+		return sigSizeWeight*math.Log(float64(p.SignatureSize())) + verifyCostWeight*math.Log(float64(p.VerifyHashes()))
+	}
+	return func(a, b *slhdsa.ParameterSet) bool {
+		return cost(a) < cost(b)
+	}
+}
+
+// readmeScenarios returns the searches from print_candidates.sh. If restricted is true, the ranges of
+// HPrime and D are restricted to the neighborhood of the README's results.
+func readmeScenarios(restricted bool) map[string]Parameters {
+	csHPrime, csD := intsBetween(1, 30), intsBetween(1, 30)
+	gpHPrime, gpD := intsBetween(1, 30), intsBetween(1, 30)
+	if restricted {
+		csHPrime, csD = intsBetween(14, 30), intsBetween(1, 3)
+		gpHPrime, gpD = intsBetween(12, 24), intsBetween(2, 4)
+	}
+	scenarios := make(map[string]Parameters)
+	for _, level := range []struct {
+		name    string
+		target  int
+		overuse int
+		maxSize int
+	}{
+		{"128", 128, 112, 4096},
+		{"192", 192, 128, 8192},
+		{"256", 256, 192, 16384},
+	} {
+		scenarios["rls"+level.name+"cs"] = Parameters{
+			TargetSecurityLevel:  level.target,
+			MinSignatures:        math.Exp2(24),
+			OveruseSecurityLevel: level.overuse,
+			MinOveruseSignatures: 1,
+			HPrime:               csHPrime,
+			D:                    csD,
+			LgW:                  intsBetween(1, 8),
+			K:                    intsBetween(1, 30),
+			T:                    intsBetween(1, 30),
+			MaxSignatureSize:     level.maxSize,
+			MaxSignatureHashes:   3000000000,
+			MaxVerifyHashes:      1000,
+			Compare:              compareLogs(0.5, 0.5),
+			CandidateCount:       20,
+		}
+		scenarios["rls"+level.name+"gp"] = Parameters{
+			TargetSecurityLevel:      level.target,
+			MinSignatures:            math.Exp2(30),
+			OveruseSecurityLevel:     level.overuse,
+			MinOveruseSignatures:     math.Exp2(40),
+			HPrime:                   gpHPrime,
+			D:                        gpD,
+			LgW:                      intsBetween(1, 8),
+			K:                        intsBetween(1, 30),
+			T:                        intsBetween(1, 30),
+			MaxSignatureSize:         level.maxSize,
+			MaxSignatureHashes:       1500000000,
+			MaxCachedSignatureHashes: 300000000,
+			MaxVerifyHashes:          100000,
+			Compare:                  compareLogs(1.0, 0),
+			CandidateCount:           20,
+		}
+	}
+	return scenarios
+}
+
+type shape struct {
+	HPrime, D, LgW, K, T int
+}
+
+func shapes(sets []slhdsa.ParameterSet) []shape {
+	result := make([]shape, len(sets))
+	for i, set := range sets {
+		result[i] = shape{set.HPrime, set.D, set.LgW, set.K, set.T}
+	}
+	return result
+}
+
+func TestPruningMatchesExhaustiveSearch(t *testing.T) {
+	for name, params := range readmeScenarios(false) {
+		t.Run(name, func(t *testing.T) {
+			pruned := Search(&params)
+			params.Exhaustive = true
+			exhaustive := Search(&params)
+			if len(pruned) == 0 {
+				t.Fatalf("Search() found no candidates")
+			}
+			if got, want := shapes(pruned), shapes(exhaustive); !slices.Equal(got, want) {
+				t.Errorf("Search() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadmeParameterSets(t *testing.T) {
+	// The first few parameter sets for each scenario in the README.
+	for name, want := range map[string][]shape{
+		"rls128cs": {{22, 1, 2, 6, 24}, {23, 1, 2, 6, 24}, {22, 1, 2, 7, 21}},
+		"rls192cs": {{21, 1, 3, 9, 25}, {21, 1, 3, 10, 23}, {22, 1, 3, 10, 23}},
+		"rls256cs": {{21, 1, 2, 12, 25}, {22, 1, 2, 13, 23}, {21, 1, 2, 14, 22}},
+		"rls128gp": {{15, 3, 8, 5, 23}, {15, 3, 8, 6, 19}, {14, 3, 8, 5, 24}},
+		"rls192gp": {{16, 2, 8, 9, 23}, {17, 2, 7, 9, 22}, {18, 2, 6, 9, 21}},
+		"rls256gp": {{17, 2, 7, 13, 21}, {15, 3, 8, 11, 22}, {14, 3, 8, 13, 19}},
+	} {
+		params := readmeScenarios(false)[name]
+		t.Run(name, func(t *testing.T) {
+			got := shapes(Search(&params))
+			if len(got) < len(want) || !slices.Equal(got[:len(want)], want) {
+				t.Errorf("Search() = %v, want prefix %v", got, want)
+			}
+		})
+	}
+}
+
+// TestConstraints checks that every parameter set found with each constraint or ranking option meets
+// it, and that pruning finds the same parameter sets as an exhaustive search.
+func TestConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		// Applies the option to the README's rls128cs search
+		apply func(params *Parameters)
+		// Returns an error if the parameter set does not meet the option
+		check func(params *Parameters, set slhdsa.ParameterSet) error
+		// If set, some parameter set found must satisfy this, to show that the option made a difference
+		some func(params *Parameters, set slhdsa.ParameterSet) bool
+	}{
+		{
+			name: "overuse tiers",
+			apply: func(params *Parameters) {
+				params.OveruseTiers = []OveruseTier{
+					{SecurityLevel: 112, MinSignatures: math.Exp2(28)},
+					{SecurityLevel: 96, MinSignatures: math.Exp2(32)},
+				}
+			},
+			check: func(params *Parameters, set slhdsa.ParameterSet) error {
+				for _, tier := range params.OveruseTiers {
+					if got := set.SecurityLevel(math.Log2(tier.MinSignatures)); got < float64(tier.SecurityLevel) {
+						return fmt.Errorf("security level %v at %v signatures, want at least %v", got, tier.MinSignatures, tier.SecurityLevel)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name: "security floor",
+			apply: func(params *Parameters) {
+				params.SecurityFloor = slhdsa.SecurityFloor{
+					{Signatures: 24, SecurityLevel: 128},
+					{Signatures: 30, SecurityLevel: 104},
+					{Signatures: 34, SecurityLevel: 80},
+				}
+			},
+			check: func(params *Parameters, set slhdsa.ParameterSet) error {
+				for _, point := range params.SecurityFloor {
+					if got := set.SecurityLevel(point.Signatures); got < point.SecurityLevel {
+						return fmt.Errorf("security level %v at 2^%v signatures, below the floor at %v", got, point.Signatures, point.SecurityLevel)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name: "filter",
+			apply: func(params *Parameters) {
+				params.Filter = func(p *slhdsa.ParameterSet) bool { return p.LgW != 2 }
+			},
+			check: func(params *Parameters, set slhdsa.ParameterSet) error {
+				if set.LgW == 2 {
+					return errors.New("lg_w 2, which the filter rejects")
+				}
+				return nil
+			},
+		},
+		{
+			name: "sign cores",
+			apply: func(params *Parameters) {
+				params.SignCores = 32
+				params.MaxSignatureHashes = 1 << 24
+				params.Compare = func(a, b *slhdsa.ParameterSet) bool {
+					return a.ParallelSignatureCost(32) < b.ParallelSignatureCost(32)
+				}
+			},
+			check: func(params *Parameters, set slhdsa.ParameterSet) error {
+				if cost := set.ParallelSignatureCost(32); cost > params.MaxSignatureHashes {
+					return fmt.Errorf("%v hashes to sign on 32 cores", cost)
+				}
+				return nil
+			},
+			// Some sets too slow to sign on a single core are fast enough on 32
+			some: func(params *Parameters, set slhdsa.ParameterSet) bool {
+				return set.SignatureCost() > params.MaxSignatureHashes
+			},
+		},
+		{
+			name: "hypertree cache",
+			apply: func(params *Parameters) {
+				params.Cache = &slhdsa.HypertreeCache{Layers: 1, Levels: 2}
+				params.MaxCachedSignatureHashes = 1 << 26
+				params.MaxCacheBytes = 1 << 26
+			},
+			check: func(params *Parameters, set slhdsa.ParameterSet) error {
+				if bytes := params.Cache.Bytes(set); bytes > params.MaxCacheBytes {
+					return fmt.Errorf("a %v-byte cache", bytes)
+				}
+				if hashes := params.Cache.SignatureHashes(set); hashes > params.MaxCachedSignatureHashes {
+					return fmt.Errorf("%v hashes to sign with the cache", hashes)
+				}
+				return nil
+			},
+		},
+		{
+			name: "worst-case verify cost",
+			apply: func(params *Parameters) {
+				params.MaxVerifyHashes = 500
+				params.VerifyCost = func(p *slhdsa.ParameterSet) int64 { return p.VerifyCostAt(100) }
+			},
+			check: func(params *Parameters, set slhdsa.ParameterSet) error {
+				if hashes := set.WorstVerifyHashes(); hashes > params.MaxVerifyHashes {
+					return fmt.Errorf("up to %v hashes to verify", hashes)
+				}
+				return nil
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			params := readmeScenarios(true)["rls128cs"]
+			tc.apply(&params)
+			got := Search(&params)
+			if len(got) == 0 {
+				t.Fatalf("Search() found no candidates")
+			}
+			for _, set := range got {
+				if err := tc.check(&params, set); err != nil {
+					t.Errorf("Search() = %v, with %v", shapes([]slhdsa.ParameterSet{set}), err)
+				}
+			}
+			if tc.some != nil && !slices.ContainsFunc(got, func(set slhdsa.ParameterSet) bool { return tc.some(&params, set) }) {
+				t.Errorf("Search() = %v, which the option made no difference to", shapes(got))
+			}
+			params.Exhaustive = true
+			if want := Search(&params); !slices.Equal(shapes(got), shapes(want)) {
+				t.Errorf("Search() = %v, want %v", shapes(got), shapes(want))
+			}
+		})
+	}
+}
+
+func TestSecurityFloorMatchesTiers(t *testing.T) {
+	tiers := readmeScenarios(true)["rls128cs"]
+	tiers.OveruseTiers = []OveruseTier{
+		{SecurityLevel: 112, MinSignatures: math.Exp2(28)},
+		{SecurityLevel: 96, MinSignatures: math.Exp2(32)},
+	}
+
+	// A floor through the same points as the tiers must find the same candidates.
+	floor := readmeScenarios(true)["rls128cs"]
+	floor.SecurityFloor = slhdsa.SecurityFloor{
+		{Signatures: 28, SecurityLevel: 112},
+		{Signatures: 32, SecurityLevel: 96},
+	}
+	got := Search(&floor)
+	if len(got) == 0 {
+		t.Fatalf("Search() found no candidates")
+	}
+	if want := Search(&tiers); !slices.Equal(shapes(got), shapes(want)) {
+		t.Errorf("Search() = %v, want %v", shapes(got), shapes(want))
+	}
+}
+
+func TestSearchContextCancelled(t *testing.T) {
+	params := readmeScenarios(false)["rls128cs"]
+	params.Exhaustive = true
+	var last Progress
+	params.Progress = func(p Progress) { last = p }
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SearchContext(ctx, &params); !errors.Is(err, context.Canceled) {
+		t.Errorf("SearchContext() = %v, want %v", err, context.Canceled)
+	}
+	// Batches handed out after the cancellation are not evaluated
+	if last.Evaluated != 0 {
+		t.Errorf("Evaluated = %v, want 0", last.Evaluated)
+	}
+}
+
+func TestSearchContextCancelledAfterCompletion(t *testing.T) {
+	params := readmeScenarios(true)["rls128cs"]
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The final progress report comes after every batch has been evaluated
+	params.Progress = func(p Progress) {
+		if p.Evaluated == p.Total {
+			cancel()
+		}
+	}
+	result, err := SearchContext(ctx, &params)
+	if err != nil {
+		t.Fatalf("SearchContext() = %v, want nil for a complete search", err)
+	}
+	params.Progress = nil
+	if want := Search(&params); !slices.Equal(shapes(result), shapes(want)) {
+		t.Errorf("SearchContext() = %v, want %v", shapes(result), shapes(want))
+	}
+}
+
+func TestSearchContextProgress(t *testing.T) {
+	params := readmeScenarios(false)["rls128cs"]
+	var last Progress
+	params.Progress = func(p Progress) { last = p }
+	result, err := SearchContext(context.Background(), &params)
+	if err != nil {
+		t.Fatalf("SearchContext() = %v", err)
+	}
+	if last.Evaluated != last.Total {
+		t.Errorf("Evaluated = %v, want %v", last.Evaluated, last.Total)
+	}
+	if last.Feasible < int64(len(result)) {
+		t.Errorf("Feasible = %v, want at least %v", last.Feasible, len(result))
+	}
+	if last.Best == nil || shapes([]slhdsa.ParameterSet{*last.Best})[0] != shapes(result)[0] {
+		t.Errorf("Best = %v, want %v", last.Best, result[0])
+	}
+}
+
+func TestPareto(t *testing.T) {
+	params := readmeScenarios(true)["rls128cs"]
+	frontier := Pareto(&params)
+	if len(frontier) == 0 {
+		t.Fatalf("Pareto() found no candidates")
+	}
+	objectives := make([]Objectives, len(frontier))
+	for i := range frontier {
+		objectives[i] = params.Objectives(&frontier[i])
+	}
+	for i := range frontier {
+		for j := range frontier {
+			if objectives[i].Dominates(objectives[j]) {
+				t.Errorf("%v dominates %v", shapes(frontier[i:i+1]), shapes(frontier[j:j+1]))
+			}
+		}
+	}
+
+	// The smallest signatures must be on the frontier.
+	params.Compare = compareLogs(1.0, 0)
+	params.CandidateCount = 1
+	best := Search(&params)[0]
+	smallest := slices.MinFunc(objectives, func(a, b Objectives) int { return a.SignatureSize - b.SignatureSize })
+	if got, want := smallest.SignatureSize, best.SignatureSize(); got != want {
+		t.Errorf("smallest signature on the frontier = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/chrisfenner/slh-dsa-rls/pkg/slhdsa"
+)
+
+// collector accumulates the candidates found by a search.
+type collector interface {
+	// insert considers the candidate for inclusion in the result.
+	insert(candidate *slhdsa.ParameterSet)
+	// result returns the candidates collected so far.
+	result() []slhdsa.ParameterSet
+}
+
+// topK keeps the best `n` candidates seen so far, ordered best first.
+type topK struct {
+	better func(a, b *slhdsa.ParameterSet) bool
+	n      int
+	sets   []slhdsa.ParameterSet
+}
+
+func newTopK(n int, better func(a, b *slhdsa.ParameterSet) bool) *topK {
+	return &topK{
+		better: better,
+		n:      n,
+		sets:   make([]slhdsa.ParameterSet, 0, n+1),
+	}
+}
+
+// insert adds the candidate if it is among the best `n` seen so far.
+func (t *topK) insert(candidate *slhdsa.ParameterSet) {
+	if t.n <= 0 {
+		return
+	}
+	// Fast path: the list is full and the candidate is no better than the worst entry.
+	if len(t.sets) == t.n && !t.better(candidate, &t.sets[len(t.sets)-1]) {
+		return
+	}
+	i := sort.Search(len(t.sets), func(i int) bool { return t.better(candidate, &t.sets[i]) })
+	t.sets = slices.Insert(t.sets, i, *candidate)
+	if len(t.sets) > t.n {
+		t.sets = t.sets[:t.n]
+	}
+}
+
+func (t *topK) result() []slhdsa.ParameterSet {
+	return t.sets
+}
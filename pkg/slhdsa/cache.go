@@ -0,0 +1,93 @@
+package slhdsa
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// securityKey identifies a security level computation. The security level of a parameter set
+// depends only on these values, so parameter sets that differ only in how the hypertree is split
+// into layers, or in the Winternitz parameter, share the same result.
+type securityKey struct {
+	k     int
+	t     int
+	h     int
+	model string
+	m     float64
+}
+
+const (
+	// The number of independently locked shards in the shared security cache
+	securityCacheShards = 64
+	// The maximum number of entries in each shard, after which the shard is cleared
+	securityCacheShardSize = 1 << 12
+)
+
+// securityCache memoizes security level computations across all parameter sets. It is safe for
+// concurrent use.
+type securityCache struct {
+	seed   maphash.Seed
+	shards [securityCacheShards]securityCacheShard
+}
+
+type securityCacheShard struct {
+	mu     sync.RWMutex
+	levels map[securityKey]float64
+}
+
+// sharedSecurityCache is the cache used by uncappedSecurityLevel.
+var sharedSecurityCache = newSecurityCache()
+
+func newSecurityCache() *securityCache {
+	c := &securityCache{seed: maphash.MakeSeed()}
+	for i := range c.shards {
+		c.shards[i].levels = make(map[securityKey]float64)
+	}
+	return c
+}
+
+// securityLevel returns the security level for the given key, calling compute to compute it if it
+// has not been cached.
+func (c *securityCache) securityLevel(key securityKey, compute func() float64) float64 {
+	shard := &c.shards[maphash.Comparable(c.seed, key)%securityCacheShards]
+
+	shard.mu.RLock()
+	level, ok := shard.levels[key]
+	shard.mu.RUnlock()
+	if ok {
+		return level
+	}
+
+	// Compute outside of the lock; concurrent callers may duplicate the work, but will agree on
+	// the result.
+	level = compute()
+
+	shard.mu.Lock()
+	// Keep the memory used by the cache bounded by starting over once a shard is full.
+	if len(shard.levels) >= securityCacheShardSize {
+		clear(shard.levels)
+	}
+	shard.levels[key] = level
+	shard.mu.Unlock()
+	return level
+}
+
+// securityKey returns the key identifying the security level of the parameter set for 2^m
+// signatures.
+func (p ParameterSet) securityKey(m float64) securityKey {
+	return securityKey{
+		k:     p.K,
+		t:     p.T,
+		h:     p.HypertreeHeight(),
+		model: p.securityModel().Name(),
+		m:     m,
+	}
+}
+
+// uncappedSecurityLevel returns the security level of the parameter set for 2^m signatures, before
+// it is limited to between 0 and the target security level, using the shared cache.
+func (p ParameterSet) uncappedSecurityLevel(m float64) float64 {
+	return sharedSecurityCache.securityLevel(p.securityKey(m), func() float64 {
+		return p.securityModel().SecurityLevel(p, m)
+	})
+}
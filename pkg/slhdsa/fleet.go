@@ -0,0 +1,54 @@
+package slhdsa
+
+import (
+	"math"
+)
+
+// A fleet is a number of keys that all use the same parameter set. A forger wins by forging a
+// signature for any one of them.
+//
+// The fleet security level is a conservative union bound: the probability of forging for any of the
+// keys is at most the sum of the probabilities of forging for each of them, as if every forgery
+// query could be tried against every key at once. Because H_msg binds the public key, a query is
+// really only good against one key, so the actual loss of security is smaller.
+
+// The security level of each key in a fleet of the given number of keys, for 2^m signatures per key
+func (p ParameterSet) FleetSecurityLevel(m float64, keys int) float64 {
+	return p.limitSecurityLevel(p.uncappedSecurityLevel(m) - math.Log2(float64(max(keys, 1))))
+}
+
+// Checks if the parameter set meets its target security level in a fleet of the given number of
+// keys, for 2^m signatures per key
+func (p ParameterSet) CheckFleetSecurityLevel(m float64, keys int) bool {
+	return p.fleetSecurityLevelAtLeast(m, keys, p.TargetSecurityLevel)
+}
+
+// Checks if the parameter set meets its target overuse security level in a fleet of the given
+// number of keys, for 2^m signatures per key
+func (p ParameterSet) CheckFleetOveruseSecurityLevel(m float64, keys int) bool {
+	return p.fleetSecurityLevelAtLeast(m, keys, p.OveruseSecurityLevel)
+}
+
+// fleetSecurityLevelAtLeast reports whether the fleet security level for 2^m signatures per key is at
+// least the given level, without computing it if the security model's bound rules it out.
+func (p ParameterSet) fleetSecurityLevelAtLeast(m float64, keys int, level int) bool {
+	if bounder, ok := p.securityModel().(securityBounder); ok {
+		if bounder.securityLevelBound(p, m)-math.Log2(float64(max(keys, 1))) < float64(level) {
+			return false
+		}
+	}
+	return p.FleetSecurityLevel(m, keys) >= float64(level)
+}
+
+// The log_2 of the total number of signatures that can be performed across a fleet of the given
+// number of keys while retaining the security level, if the signatures are spread evenly across the
+// keys
+func (p ParameterSet) FleetSignaturesAtLevel(target int, keys int) float64 {
+	if target <= 0 {
+		return math.Inf(1)
+	}
+	perKey := p.signaturesRetaining(func(m float64) bool {
+		return p.FleetSecurityLevel(m, keys) >= float64(target)
+	}, DefaultSignaturesPrecision)
+	return perKey + math.Log2(float64(max(keys, 1)))
+}
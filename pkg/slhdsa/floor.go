@@ -0,0 +1,99 @@
+package slhdsa
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FloorPoint is a breakpoint of a SecurityFloor.
+type FloorPoint struct {
+	// The log_2 of the number of signatures
+	Signatures float64
+	// The minimum security level in bits after that many signatures
+	SecurityLevel float64
+}
+
+// SecurityFloor is a piecewise-linear curve of the minimum acceptable security level against the
+// log_2 of the number of signatures, given by its breakpoints in increasing order of signatures.
+type SecurityFloor []FloorPoint
+
+// NewSecurityFloor returns the security floor with the given breakpoints, which must be in strictly
+// increasing order of signatures.
+func NewSecurityFloor(points []FloorPoint) (SecurityFloor, error) {
+	if len(points) == 0 {
+		return nil, errors.New("security floor has no points")
+	}
+	for i := 1; i < len(points); i++ {
+		if points[i].Signatures <= points[i-1].Signatures {
+			return nil, fmt.Errorf("security floor points must be in increasing order of signatures, got %v after %v", points[i].Signatures, points[i-1].Signatures)
+		}
+	}
+	return SecurityFloor(points), nil
+}
+
+// ParseSecurityFloor reads a security floor from CSV with one breakpoint per record, each of the
+// form (log_2 signatures, security level). The first record may be a header.
+func ParseSecurityFloor(r io.Reader) (SecurityFloor, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not read security floor: %v", err)
+	}
+
+	var points []FloorPoint
+	for i, record := range records {
+		signatures, err := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		if err != nil && i == 0 {
+			// Skip the header
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not parse signatures from %q: %v", record[0], err)
+		}
+		securityLevel, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse security level from %q: %v", record[1], err)
+		}
+		points = append(points, FloorPoint{Signatures: signatures, SecurityLevel: securityLevel})
+	}
+	return NewSecurityFloor(points)
+}
+
+// At returns the minimum acceptable security level after 2^m signatures. Before the first
+// breakpoint and after the last, the floor is flat.
+func (f SecurityFloor) At(m float64) float64 {
+	if m <= f[0].Signatures {
+		return f[0].SecurityLevel
+	}
+	for i := 1; i < len(f); i++ {
+		if m <= f[i].Signatures {
+			from, to := f[i-1], f[i]
+			return from.SecurityLevel + (m-from.Signatures)/(to.Signatures-from.Signatures)*(to.SecurityLevel-from.SecurityLevel)
+		}
+	}
+	return f[len(f)-1].SecurityLevel
+}
+
+// The smallest margin (in bits) by which the security level of the parameter set exceeds the floor,
+// which is negative if the parameter set dips below it
+// The security level is only evaluated at the breakpoints of the floor.
+func (p ParameterSet) FloorMargin(floor SecurityFloor) float64 {
+	return p.FleetFloorMargin(floor, 1)
+}
+
+// The smallest margin (in bits) by which the security level of each key in a fleet of the given
+// number of keys exceeds the floor, evaluated at the breakpoints of the floor
+func (p ParameterSet) FleetFloorMargin(floor SecurityFloor, keys int) float64 {
+	margin := math.Inf(1)
+	for _, point := range floor {
+		margin = math.Min(margin, p.FleetSecurityLevel(point.Signatures, keys)-point.SecurityLevel)
+	}
+	return margin
+}
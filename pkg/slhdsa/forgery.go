@@ -0,0 +1,14 @@
+package slhdsa
+
+import (
+	"math"
+)
+
+// The probability that an attacker making 2^q hash queries can forge a signature after 2^m
+// signatures
+// Each query succeeds independently, with probability 2^-s where s is the security level.
+func (p ParameterSet) ForgeryProbability(m, q float64) float64 {
+	perQuery := math.Exp2(-p.SecurityLevel(m))
+	// 1 - (1 - perQuery)^{2^q}, without losing precision when perQuery is tiny
+	return -math.Expm1(math.Exp2(q) * math.Log1p(-perQuery))
+}
@@ -0,0 +1,393 @@
+package slhdsa
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// HashCalls counts the calls to each of the hash functions of SLH-DSA (FIPS 205, section 4.1).
+type HashCalls struct {
+	// PH, which pre-hashes the message for HashSLH-DSA (FIPS 205, section 10.2)
+	PreHash int64
+	// PRF_msg, which generates the randomizer of a signature
+	PRFMsg int64
+	// H_msg, which computes the message digest
+	HMsg int64
+	// PRF, which generates the secret values of the WOTS+ and FORS keys
+	PRF int64
+	// F, which hashes a single value (a step of a WOTS+ chain or a FORS leaf)
+	F int64
+	// H, which hashes two values (a node of a Merkle tree)
+	H int64
+	// T_k, which compresses the roots of a FORS key
+	TK int64
+	// T_len, which compresses the public values of a WOTS+ key
+	TLen int64
+}
+
+// Total returns the total number of calls.
+func (c HashCalls) Total() int64 {
+	return c.PreHash + c.PRFMsg + c.HMsg + c.PRF + c.F + c.H + c.TK + c.TLen
+}
+
+// Add returns the sum of the two counts of calls.
+func (c HashCalls) Add(other HashCalls) HashCalls {
+	return HashCalls{
+		PreHash: c.PreHash + other.PreHash,
+		PRFMsg:  c.PRFMsg + other.PRFMsg,
+		HMsg:    c.HMsg + other.HMsg,
+		PRF:     c.PRF + other.PRF,
+		F:       c.F + other.F,
+		H:       c.H + other.H,
+		TK:      c.TK + other.TK,
+		TLen:    c.TLen + other.TLen,
+	}
+}
+
+// Cost returns the total cost of the calls, given the cost of a single call to each hash function.
+func (c HashCalls) Cost(costs HashCalls) int64 {
+	return c.PreHash*costs.PreHash +
+		c.PRFMsg*costs.PRFMsg +
+		c.HMsg*costs.HMsg +
+		c.PRF*costs.PRF +
+		c.F*costs.F +
+		c.H*costs.H +
+		c.TK*costs.TK +
+		c.TLen*costs.TLen
+}
+
+// The calls to each hash function required to produce a signature
+// The total is SignatureHashes.
+func (p ParameterSet) SignatureHashCalls() HashCalls {
+	calls := p.CachedSignatureHashCalls()
+	// Each layer of the hypertree generates every WOTS+ key of an XMSS tree and its Merkle tree, which
+	// computes the WOTS+ signature along the way
+	leaves := int64(p.D) << p.HPrime
+	digits := int64(p.WinternitzDigits())
+	calls.PRF += leaves * digits
+	calls.F += leaves * digits * ((1 << p.LgW) - 1)
+	calls.TLen += leaves
+	calls.H += leaves - int64(p.D)
+	return calls
+}
+
+// The calls to each hash function required to produce a signature if the hypertree is cached
+// The total is CachedSignatureHashes.
+func (p ParameterSet) CachedSignatureHashCalls() HashCalls {
+	// Each FORS tree generates all of its leaves and its Merkle tree
+	leaves := int64(p.K) << p.T
+	return HashCalls{
+		PreHash: p.preHashes(),
+		PRFMsg:  1,
+		HMsg:    1,
+		PRF:     leaves,
+		F:       leaves,
+		H:       leaves - int64(p.K),
+		TK:      1,
+	}
+}
+
+// The calls to each hash function required to verify a signature, on average
+// The total is VerifyHashes.
+func (p ParameterSet) VerifyHashCalls() HashCalls {
+	return HashCalls{
+		PreHash: p.preHashes(),
+		HMsg:    1,
+		// Each FORS leaf, and each WOTS+ chain is on average halfway done
+		F: int64(p.K) + int64(p.D)*(int64(p.WinternitzDigits())*(1<<int64(p.LgW))/2),
+		// The authentication path of each FORS tree and each XMSS tree
+		H:    int64(p.K)*int64(p.T) + int64(p.D)*int64(p.HPrime),
+		TK:   1,
+		TLen: int64(p.D),
+	}
+}
+
+// Primitive is an operation that the hash functions of a HashFamily are built from.
+type Primitive string
+
+const (
+	// A call to any of the hash functions, as counted by AbstractHashes
+	PrimitiveHash Primitive = "hash"
+	// A call to the SHA-256 compression function
+	PrimitiveSHA256 Primitive = "sha256"
+	// A call to the SHA-512 compression function
+	PrimitiveSHA512 Primitive = "sha512"
+	// A call to the Keccak-f[1600] permutation
+	PrimitiveKeccak Primitive = "keccak-f"
+)
+
+// HashFamily is an instantiation of the hash functions of SLH-DSA, which determines the cost of each
+// call to them.
+type HashFamily interface {
+	// The name of the hash family, which must be unique
+	Name() string
+	// The name of the operation that costs are counted in (e.g., "SHA-2 compressions")
+	Unit() string
+	// The cost of a single call to each hash function for the parameter set
+	CallCosts(p ParameterSet) HashCalls
+	// The cost of a single call to each hash function for the parameter set, split by the primitive
+	// the cost is made up of; the costs add up to CallCosts
+	PrimitiveCosts(p ParameterSet) map[Primitive]HashCalls
+}
+
+// DefaultHashFamily is the hash family used by parameter sets that do not specify one.
+var DefaultHashFamily HashFamily = AbstractHashes{}
+
+// The available hash families, keyed by name
+var hashFamilies = map[string]HashFamily{
+	AbstractHashes{}.Name(): AbstractHashes{},
+	SHA2{}.Name():           SHA2{},
+	SHAKE{}.Name():          SHAKE{},
+}
+
+// HashFamilyByName returns the hash family with the given name.
+func HashFamilyByName(name string) (HashFamily, error) {
+	family, ok := hashFamilies[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown hash family %q (known hash families: %v)", name, strings.Join(HashFamilyNames(), ", "))
+	}
+	return family, nil
+}
+
+// HashFamilyNames returns the names of the available hash families, in sorted order.
+func HashFamilyNames() []string {
+	return slices.Sorted(maps.Keys(hashFamilies))
+}
+
+// hashFamily returns the hash family of the parameter set.
+func (p ParameterSet) hashFamily() HashFamily {
+	if p.HashFamily == nil {
+		return DefaultHashFamily
+	}
+	return p.HashFamily
+}
+
+// PrimitiveCounts returns the number of calls to each primitive of the parameter set's hash family
+// that the calls to the hash functions are made of.
+func (p ParameterSet) PrimitiveCounts(calls HashCalls) map[Primitive]int64 {
+	counts := make(map[Primitive]int64)
+	for primitive, costs := range p.hashFamily().PrimitiveCosts(p) {
+		counts[primitive] = calls.Cost(costs)
+	}
+	return counts
+}
+
+// Cost returns the cost of the calls to the hash functions, in the units of the parameter set's hash
+// family.
+func (p ParameterSet) Cost(calls HashCalls) int64 {
+	return calls.Cost(p.hashFamily().CallCosts(p))
+}
+
+// The cost of producing a signature, in the units of the parameter set's hash family
+func (p ParameterSet) SignatureCost() int64 {
+	return p.Cost(p.SignatureHashCalls())
+}
+
+// The cost of producing a signature if the hypertree is cached, in the units of the parameter set's
+// hash family
+func (p ParameterSet) CachedSignatureCost() int64 {
+	return p.Cost(p.CachedSignatureHashCalls())
+}
+
+// The cost of verifying a signature, in the units of the parameter set's hash family
+func (p ParameterSet) VerifyCost() int64 {
+	return p.Cost(p.VerifyHashCalls())
+}
+
+// DefaultMessageBytes is the length in bytes of the messages signed with parameter sets that do not
+// specify one (e.g., a digest).
+const DefaultMessageBytes = 32
+
+// The length in bytes of the DER encoding of the OID of the pre-hash function in HashSLH-DSA
+const preHashOIDBytes = 11
+
+// messageBytes returns the length in bytes of the messages signed with the parameter set.
+func (p ParameterSet) messageBytes() int {
+	if p.MessageBytes <= 0 {
+		return DefaultMessageBytes
+	}
+	return p.MessageBytes
+}
+
+// preHashes returns the number of calls to the pre-hash function to sign or verify a message.
+func (p ParameterSet) preHashes() int64 {
+	if p.PreHash {
+		return 1
+	}
+	return 0
+}
+
+// preHashBytes returns the length in bytes of the digest of the pre-hash function: 256 bits at
+// security category 1, and 512 bits above it.
+func (p ParameterSet) preHashBytes() int {
+	if ceil(p.TargetSecurityLevel, 8) > 16 {
+		return 64
+	}
+	return 32
+}
+
+// encodedMessageBytes returns the length in bytes of M', which PRF_msg and H_msg process: a domain
+// separator, the length of an empty context and either the message or the OID and digest of its
+// pre-hash (FIPS 205, algorithms 22 and 23).
+func (p ParameterSet) encodedMessageBytes() int {
+	if p.PreHash {
+		return 2 + preHashOIDBytes + p.preHashBytes()
+	}
+	return 2 + p.messageBytes()
+}
+
+// The length in bytes of a compressed address (ADRS^c), used by the SHA2 instantiation
+const compressedAddressBytes = 22
+
+// The length in bytes of an address (ADRS), used by the SHAKE instantiation
+const addressBytes = 32
+
+// AbstractHashes counts every call to a hash function as a single hash, regardless of its
+// instantiation, except that the calls that process the message count a hash for each 64 bytes of it.
+type AbstractHashes struct{}
+
+func (AbstractHashes) Name() string {
+	return "abstract"
+}
+
+func (AbstractHashes) Unit() string {
+	return "hashes"
+}
+
+// The size in bytes of the blocks of the message that AbstractHashes counts as a hash each
+const abstractBlockBytes = 64
+
+func (AbstractHashes) CallCosts(p ParameterSet) HashCalls {
+	// PRF_msg and H_msg process the message, or only its digest if it is pre-hashed
+	message := int64(max(ceil(p.messageBytes(), abstractBlockBytes), 1))
+	digest := message
+	if p.PreHash {
+		digest = int64(ceil(p.preHashBytes(), abstractBlockBytes))
+	}
+	return HashCalls{PreHash: message, PRFMsg: digest, HMsg: digest, PRF: 1, F: 1, H: 1, TK: 1, TLen: 1}
+}
+
+func (a AbstractHashes) PrimitiveCosts(p ParameterSet) map[Primitive]HashCalls {
+	return map[Primitive]HashCalls{PrimitiveHash: a.CallCosts(p)}
+}
+
+// SHA2 is the SHA2 instantiation of SLH-DSA (FIPS 205, sections 11.2.1 and 11.2.2), with costs in
+// calls to the SHA-256 or SHA-512 compression function.
+//
+// PK.seed is padded to a full block, so its compression is done once per key and not counted.
+// Above security category 1, H, T_l, H_msg and PRF_msg use SHA-512. The pre-hash is SHA-256 at
+// security category 1 and SHA-512 above it.
+type SHA2 struct{}
+
+func (SHA2) Name() string {
+	return "sha2"
+}
+
+func (SHA2) Unit() string {
+	return "SHA-2 compressions"
+}
+
+// sha256Blocks returns the number of SHA-256 compressions to hash a message of the given length.
+func sha256Blocks(bytes int) int64 {
+	// The padding is at least a 1 bit and the 64-bit length
+	return int64(ceil(bytes+9, 64))
+}
+
+// sha512Blocks returns the number of SHA-512 compressions to hash a message of the given length.
+func sha512Blocks(bytes int) int64 {
+	// The padding is at least a 1 bit and the 128-bit length
+	return int64(ceil(bytes+17, 128))
+}
+
+func (s SHA2) CallCosts(p ParameterSet) HashCalls {
+	var costs HashCalls
+	for _, primitiveCosts := range s.PrimitiveCosts(p) {
+		costs = costs.Add(primitiveCosts)
+	}
+	return costs
+}
+
+func (SHA2) PrimitiveCosts(p ParameterSet) map[Primitive]HashCalls {
+	n := ceil(p.TargetSecurityLevel, 8)
+	primitive, blocks, blockBytes, digestBytes := PrimitiveSHA256, sha256Blocks, 64, 32
+	if n > 16 {
+		primitive, blocks, blockBytes, digestBytes = PrimitiveSHA512, sha512Blocks, 128, 64
+	}
+	// HMAC hashes a block of the key XORed with ipad followed by the message, then a block of the key
+	// XORed with opad followed by the inner digest
+	hmac := blocks(blockBytes+n+p.encodedMessageBytes()) + blocks(blockBytes+digestBytes)
+	// H_msg is MGF1 over (R || PK.seed || SHA-X(R || PK.seed || PK.root || M)), with a 4-byte counter
+	mgf1 := int64(ceil(p.M(), digestBytes)) * blocks(2*n+digestBytes+4)
+	// F and PRF always use SHA-256
+	costs := map[Primitive]HashCalls{
+		PrimitiveSHA256: {
+			PRF: sha256Blocks(compressedAddressBytes + n),
+			F:   sha256Blocks(compressedAddressBytes + n),
+		},
+	}
+	costs[primitive] = costs[primitive].Add(HashCalls{
+		PreHash: blocks(p.messageBytes()),
+		PRFMsg:  hmac,
+		HMsg:    blocks(3*n+p.encodedMessageBytes()) + mgf1,
+		H:       blocks(compressedAddressBytes + 2*n),
+		TK:      blocks(compressedAddressBytes + p.K*n),
+		TLen:    blocks(compressedAddressBytes + p.WinternitzDigits()*n),
+	})
+	return costs
+}
+
+// SHAKE is the SHAKE instantiation of SLH-DSA (FIPS 205, section 11.1), with costs in calls to the
+// Keccak-f[1600] permutation. The pre-hash is SHAKE128 with a 256-bit digest at security category 1,
+// and SHAKE256 with a 512-bit digest above it.
+type SHAKE struct{}
+
+func (SHAKE) Name() string {
+	return "shake"
+}
+
+func (SHAKE) Unit() string {
+	return "Keccak-f permutations"
+}
+
+// The rates in bytes of SHAKE128 and SHAKE256
+const (
+	shake128Rate = 168
+	shake256Rate = 136
+)
+
+// keccakPermutations returns the number of Keccak-f permutations for a sponge with the given rate to
+// absorb a message of the given length and squeeze the given number of bytes.
+func keccakPermutations(rate, in, out int) int64 {
+	// The padding is at least one byte
+	return int64(ceil(in+1, rate) + ceil(out, rate) - 1)
+}
+
+// shake256Permutations returns the number of Keccak-f permutations for SHAKE256 to absorb a message of
+// the given length and squeeze the given number of bytes.
+func shake256Permutations(in, out int) int64 {
+	return keccakPermutations(shake256Rate, in, out)
+}
+
+func (SHAKE) CallCosts(p ParameterSet) HashCalls {
+	n := ceil(p.TargetSecurityLevel, 8)
+	preHashRate := shake256Rate
+	if n <= 16 {
+		preHashRate = shake128Rate
+	}
+	return HashCalls{
+		PreHash: keccakPermutations(preHashRate, p.messageBytes(), p.preHashBytes()),
+		PRFMsg:  shake256Permutations(2*n+p.encodedMessageBytes(), n),
+		HMsg:    shake256Permutations(3*n+p.encodedMessageBytes(), p.M()),
+		PRF:     shake256Permutations(n+addressBytes+n, n),
+		F:       shake256Permutations(n+addressBytes+n, n),
+		H:       shake256Permutations(n+addressBytes+2*n, n),
+		TK:      shake256Permutations(n+addressBytes+p.K*n, n),
+		TLen:    shake256Permutations(n+addressBytes+p.WinternitzDigits()*n, n),
+	}
+}
+
+func (s SHAKE) PrimitiveCosts(p ParameterSet) map[Primitive]HashCalls {
+	return map[Primitive]HashCalls{PrimitiveKeccak: s.CallCosts(p)}
+}
@@ -0,0 +1,102 @@
+package slhdsa
+
+import "math"
+
+// HypertreeCache describes which parts of the hypertree a signer keeps between signatures, trading
+// storage for signing work.
+//
+// A cached layer keeps every node of each of its XMSS trees (except the roots) and the WOTS+
+// signature of each of their leaves, so signing does no work in it. The bottom layer signs the FORS
+// public key, which depends on the message, so if it is cached it keeps every value of each WOTS+
+// chain instead, and signing picks the signature out of them. Each of the other layers keeps the top
+// Levels levels of each of its XMSS trees, so signing only builds the subtree below them that holds
+// the signing leaf, computing the WOTS+ signature along the way.
+type HypertreeCache struct {
+	// The number of layers of the hypertree that are cached, counted from the top
+	Layers int
+	// The number of levels of each XMSS tree in the other layers that are cached, counted from the top
+	Levels int
+}
+
+// layers returns the number of cached layers of the parameter set's hypertree.
+func (c HypertreeCache) layers(p ParameterSet) int {
+	return min(max(c.Layers, 0), p.D)
+}
+
+// levels returns the number of cached levels of the XMSS trees in the other layers.
+func (c HypertreeCache) levels(p ParameterSet) int {
+	return min(max(c.Levels, 0), p.HPrime)
+}
+
+// The calls to each hash function required to produce a signature with the cache
+// With no cache, this is SignatureHashCalls; with every layer cached, CachedSignatureHashCalls.
+func (c HypertreeCache) SignatureHashCalls(p ParameterSet) HashCalls {
+	calls := p.CachedSignatureHashCalls()
+	// Each uncached layer generates every WOTS+ key below the cached levels of an XMSS tree and the
+	// Merkle tree up to them
+	layers := int64(p.D - c.layers(p))
+	leaves := int64(1) << (p.HPrime - c.levels(p))
+	digits := int64(p.WinternitzDigits())
+	calls.PRF += layers * leaves * digits
+	calls.F += layers * leaves * digits * ((1 << p.LgW) - 1)
+	calls.TLen += layers * leaves
+	calls.H += layers * (leaves - 1)
+	return calls
+}
+
+// The calls to the hash functions on each chain of dependent calls in producing a signature with the
+// cache (see SignaturePaths)
+func (c HypertreeCache) SignaturePaths(p ParameterSet) []HashCalls {
+	paths := p.CachedSignaturePaths()
+	if c.layers(p) < p.D {
+		paths = append(paths, HashCalls{
+			PreHash: p.preHashes(),
+			PRFMsg:  1,
+			HMsg:    1,
+			PRF:     1,
+			F:       (1 << p.LgW) - 1,
+			TLen:    1,
+			H:       int64(p.HPrime - c.levels(p)),
+		})
+	}
+	return paths
+}
+
+// The number of hash operations required to produce a signature with the cache
+func (c HypertreeCache) SignatureHashes(p ParameterSet) int64 {
+	return c.SignatureHashCalls(p).Total()
+}
+
+// The cost of producing a signature with the cache, in the units of the parameter set's hash family
+func (c HypertreeCache) SignatureCost(p ParameterSet) int64 {
+	return p.Cost(c.SignatureHashCalls(p))
+}
+
+// The cost of producing a signature with the cache and the given number of parallel hash units, in
+// the units of the parameter set's hash family (see ParallelCost)
+func (c HypertreeCache) ParallelSignatureCost(p ParameterSet, units int) int64 {
+	return ParallelCost(c.SignatureCost(p), p.span(c.SignaturePaths(p)), units)
+}
+
+// The size in bytes of the cache. This is a float64, since caching the lower layers of a large
+// hypertree takes far more than 2^63 bytes.
+func (c HypertreeCache) Bytes(p ParameterSet) float64 {
+	n := float64(ceil(p.TargetSecurityLevel, 8))
+	layers, levels := c.layers(p), c.levels(p)
+	var nodes float64
+	for layer := range p.D {
+		// Counting from the top, each layer has 2^h' times as many XMSS trees as the one above it
+		trees := math.Exp2(float64(layer * p.HPrime))
+		if layer < layers {
+			// A WOTS+ signature per leaf, or in the bottom layer, all w values of each chain
+			values := float64(p.WinternitzDigits())
+			if layer == p.D-1 {
+				values *= float64(int(1) << p.LgW)
+			}
+			nodes += trees * (math.Exp2(float64(p.HPrime+1)) - 2 + math.Exp2(float64(p.HPrime))*values)
+		} else {
+			nodes += trees * (math.Exp2(float64(levels+1)) - 2)
+		}
+	}
+	return n * nodes
+}
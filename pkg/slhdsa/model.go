@@ -0,0 +1,218 @@
+package slhdsa
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// SecurityModel is an analysis of the security of a parameter set against forgeries after many
+// signatures.
+//
+// The search relies on the security level depending only on K, T and the hypertree height of the
+// parameter set (and the number of signatures), and on it increasing with each of them.
+type SecurityModel interface {
+	// The name of the model, which must be unique
+	Name() string
+	// The security level of the parameter set for 2^m signatures, before it is limited to between 0
+	// and the target security level
+	SecurityLevel(p ParameterSet, m float64) float64
+}
+
+// securityBounder is implemented by security models that can cheaply bound their security level from
+// above, so that parameter sets far below a level can be ruled out without computing it exactly.
+type securityBounder interface {
+	// An upper bound on SecurityLevel(p, m)
+	securityLevelBound(p ParameterSet, m float64) float64
+}
+
+// DefaultSecurityModel is the security model used by parameter sets that do not specify one.
+var DefaultSecurityModel SecurityModel = Fluhrer{}
+
+// The available security models, keyed by name
+var securityModels = map[string]SecurityModel{
+	Fluhrer{}.Name():     Fluhrer{},
+	SPHINCSPlus{}.Name(): SPHINCSPlus{},
+}
+
+// SecurityModelByName returns the security model with the given name.
+func SecurityModelByName(name string) (SecurityModel, error) {
+	model, ok := securityModels[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown security model %q (known models: %v)", name, strings.Join(SecurityModelNames(), ", "))
+	}
+	return model, nil
+}
+
+// SecurityModelNames returns the names of the available security models, in sorted order.
+func SecurityModelNames() []string {
+	return slices.Sorted(maps.Keys(securityModels))
+}
+
+// securityModel returns the security model of the parameter set.
+func (p ParameterSet) securityModel() SecurityModel {
+	if p.SecurityModel == nil {
+		return DefaultSecurityModel
+	}
+	return p.SecurityModel
+}
+
+// Fluhrer is Scott Fluhrer's analysis of FORS reuse, which approximates the number of signatures
+// that use each hypertree leaf as Poisson distributed.
+type Fluhrer struct{}
+
+func (Fluhrer) Name() string {
+	return "fluhrer"
+}
+
+// Computes the exact security level of the parameter set for 2^m signatures
+// This is a Go translation of Scott Fluhrer's algorithm `compute_sec_level` from
+// https://github.com/sfluhrer/sphincs-param-set-search/blob/main/gamma.c
+func (Fluhrer) SecurityLevel(p ParameterSet, m float64) float64 {
+	// Lambda is the expected number of signatures per hypertree leaf at the specified number of signatures.
+	lambda := 0.0
+	if m > float64(p.HypertreeHeight()) {
+		lambda = math.Exp2(m - float64(p.HypertreeHeight()))
+	} else {
+		lambda = math.Pow(0.5, float64(p.HypertreeHeight())-m)
+	}
+	log_lambda := m - float64(p.HypertreeHeight())
+
+	// This is the probability that a probe does not hit a specific valid signature within a specific FORS tree
+	prob_not_get_single_hit := 1.0 - math.Pow(0.5, float64(p.T))
+
+	// This is the probability that no probes hit a specific valid signature in a specific FORS tree
+	// after g signatures have been generated from this FORS.
+	// This is updated as g is iterated.
+	prob_not_get_g_hit := 1.0
+
+	// a == lambda^g
+	log_a := 0.0
+
+	// the running sum
+	log_sum := 0.0
+
+	for g := 1; ; g++ {
+		// Update the variables that depend on g
+		log_a += float64(log_lambda)
+		log_a -= math.Log2(float64(g))
+		prob_not_get_g_hit *= prob_not_get_single_hit
+
+		// a is the probability that there will be precisely g valid signatures
+		// for this FORS (except for the constant e^{-\lambda} term; we'll
+		// account for that at the end)
+
+		// Compute b which is probability that a single forgery query will lie
+		// entirely in revealed FORS leaves (and thus will allow a signature
+		// of that forgery), assuming we have precisely g valid signatures for
+		// this FORS
+		log_b := logForgeryHit(p.K, prob_not_get_g_hit)
+
+		// Hence, the probability that this iteration adds to the sum is
+		// a*b, and since we're dealing with logs, log(ab) = log(a) + log(b)
+		if g == 1 {
+			// For the first iteration, the running sum is the first output
+			log_sum = log_a + log_b
+		} else {
+			// For latter iterations, add log(ab) to the running sum
+			log_sum = addLogs(log_sum, log_a+log_b)
+		}
+
+		// If the additional terms we're seeing is less than 2^{-20} of the
+		// sum, any further terms won't change the answer much - we might as
+		// well stop.  We test against log_a, as that is strictly decreasing
+		// and bounds the probability (as log_b < 0)
+		if g >= 10 && log_sum > 20+log_a {
+			break
+		}
+	}
+
+	return lambda*math.Log2(math.E) - log_sum
+}
+
+// The number of bits by which securityLevelBound overestimates to cover the rounding of the running
+// sums in SecurityLevel
+const securityBoundSlack = 1
+
+// securityLevelBound bounds SecurityLevel from above by a single term of its sum, for nearly lambda
+// signatures per hypertree leaf. The terms grow by a factor of lambda/g up to there, so the sum cannot
+// reach 2^20 times the last a before it and the loop always includes the term. It only saves work
+// (and is only computed) when lambda is large, where the loop takes about lambda iterations.
+func (Fluhrer) securityLevelBound(p ParameterSet, m float64) float64 {
+	log_lambda := m - float64(p.HypertreeHeight())
+	if log_lambda < 10 {
+		return math.Inf(1)
+	}
+	lambda := math.Exp2(log_lambda)
+	g := math.Floor(lambda * (1 - math.Exp2(-20)))
+	log_g_factorial, _ := math.Lgamma(g + 1)
+	log_a := g*log_lambda - log_g_factorial/math.Ln2
+	prob_not_get_g_hit := math.Exp(g * math.Log1p(-math.Pow(0.5, float64(p.T))))
+	log_b := logForgeryHit(p.K, prob_not_get_g_hit)
+	return lambda*math.Log2(math.E) - (log_a + log_b) + securityBoundSlack
+}
+
+// Computes log_2 of the probability that a single forgery query will lie entirely in revealed FORS
+// leaves, given the probability that no probes hit a specific valid signature in a specific FORS tree
+func logForgeryHit(k int, prob_not_get_g_hit float64) float64 {
+	if prob_not_get_g_hit < 0.00001 {
+		// If prob_not_get_g_hit is sufficiently small, the subtraction
+		// will lose significant bits (or just result in 1)
+		// In this regime, the quadratic approximation, that is, the first
+		// two terms in the Taylor expansion, gives us a more accurate value
+		return float64(-k) * (prob_not_get_g_hit/math.Log(2.0) +
+			prob_not_get_g_hit*prob_not_get_g_hit/(2*math.Log(2.0)))
+	}
+	return float64(k) * math.Log2(1-prob_not_get_g_hit)
+}
+
+// SPHINCSPlus is the bound on FORS forgeries from the original SPHINCS+ submission, which counts the
+// number of signatures that use each hypertree leaf exactly, as binomially distributed.
+type SPHINCSPlus struct{}
+
+func (SPHINCSPlus) Name() string {
+	return "sphincs+"
+}
+
+// Computes the security level of the parameter set for 2^m signatures
+// This is the sum over g of the probability that precisely g of the signatures use a given
+// hypertree leaf, times the probability that a single forgery query lies entirely in the FORS
+// leaves revealed by those g signatures.
+func (SPHINCSPlus) SecurityLevel(p ParameterSet, m float64) float64 {
+	// The number of signatures
+	q := math.Exp2(m)
+	// The log_2 of the probability that a signature does and does not use a given hypertree leaf
+	log_leaf := -float64(p.HypertreeHeight())
+	log_not_leaf := math.Log1p(-math.Exp2(log_leaf)) / math.Ln2
+
+	// This is the probability that a probe does not hit a specific valid signature within a specific FORS tree
+	prob_not_get_single_hit := 1.0 - math.Pow(0.5, float64(p.T))
+
+	// This is the probability that no probes hit a specific valid signature in a specific FORS tree
+	// after g signatures have been generated from this FORS.
+	prob_not_get_g_hit := 1.0
+
+	// a is the probability that precisely g signatures use a given hypertree leaf, starting at g = 0
+	log_a := q * log_not_leaf
+
+	// the running sum
+	log_sum := math.Inf(-1)
+
+	for g := 1; float64(g) <= q; g++ {
+		// Update the variables that depend on g
+		log_a += math.Log2(q-float64(g)+1) - math.Log2(float64(g)) + log_leaf - log_not_leaf
+		prob_not_get_g_hit *= prob_not_get_single_hit
+
+		log_sum = addLogs(log_sum, log_a+logForgeryHit(p.K, prob_not_get_g_hit))
+
+		// Past the expected number of signatures per leaf, a is decreasing, and once the terms are
+		// less than 2^{-20} of the sum, any further terms won't change the answer much.
+		if g >= 10 && float64(g) > q*math.Exp2(log_leaf) && log_sum > 20+log_a {
+			break
+		}
+	}
+
+	return -log_sum
+}
@@ -0,0 +1,80 @@
+package slhdsa
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The standard FIPS 205 parameter sets (which are the same for SHA2 and SHAKE), along with the top
+// suggested parameter sets from the README
+var namedParameterSets = map[string]ParameterSet{}
+
+func init() {
+	for _, set := range []struct {
+		name string
+		ParameterSet
+	}{
+		{"128s", ParameterSet{TargetSecurityLevel: 128, OveruseSecurityLevel: 112, HPrime: 9, D: 7, T: 12, K: 14, LgW: 4}},
+		{"128f", ParameterSet{TargetSecurityLevel: 128, OveruseSecurityLevel: 112, HPrime: 3, D: 22, T: 6, K: 33, LgW: 4}},
+		{"192s", ParameterSet{TargetSecurityLevel: 192, OveruseSecurityLevel: 128, HPrime: 9, D: 7, T: 14, K: 17, LgW: 4}},
+		{"192f", ParameterSet{TargetSecurityLevel: 192, OveruseSecurityLevel: 128, HPrime: 3, D: 22, T: 8, K: 33, LgW: 4}},
+		{"256s", ParameterSet{TargetSecurityLevel: 256, OveruseSecurityLevel: 192, HPrime: 8, D: 8, T: 14, K: 22, LgW: 4}},
+		{"256f", ParameterSet{TargetSecurityLevel: 256, OveruseSecurityLevel: 192, HPrime: 4, D: 17, T: 9, K: 35, LgW: 4}},
+	} {
+		namedParameterSets["SLH-DSA-SHA2-"+set.name] = set.ParameterSet
+		namedParameterSets["SLH-DSA-SHAKE-"+set.name] = set.ParameterSet
+	}
+
+	namedParameterSets["rls128cs1"] = ParameterSet{TargetSecurityLevel: 128, OveruseSecurityLevel: 112, HPrime: 22, D: 1, T: 24, K: 6, LgW: 2}
+	namedParameterSets["rls192cs1"] = ParameterSet{TargetSecurityLevel: 192, OveruseSecurityLevel: 128, HPrime: 21, D: 1, T: 25, K: 9, LgW: 3}
+	namedParameterSets["rls256cs1"] = ParameterSet{TargetSecurityLevel: 256, OveruseSecurityLevel: 192, HPrime: 21, D: 1, T: 25, K: 12, LgW: 2}
+	namedParameterSets["rls128gp1"] = ParameterSet{TargetSecurityLevel: 128, OveruseSecurityLevel: 112, HPrime: 15, D: 3, T: 23, K: 5, LgW: 8}
+	namedParameterSets["rls192gp1"] = ParameterSet{TargetSecurityLevel: 192, OveruseSecurityLevel: 128, HPrime: 16, D: 2, T: 23, K: 9, LgW: 8}
+	namedParameterSets["rls256gp1"] = ParameterSet{TargetSecurityLevel: 256, OveruseSecurityLevel: 192, HPrime: 17, D: 2, T: 21, K: 13, LgW: 7}
+}
+
+// ParameterSetByName returns the named parameter set (e.g., "SLH-DSA-SHA2-128s" or "rls128cs1").
+// Names are not case sensitive.
+func ParameterSetByName(name string) (ParameterSet, error) {
+	for known, set := range namedParameterSets {
+		if strings.EqualFold(known, name) {
+			return set, nil
+		}
+	}
+	return ParameterSet{}, fmt.Errorf("unknown parameter set %q (known parameter sets: %v)", name, strings.Join(ParameterSetNames(), ", "))
+}
+
+// ParameterSetNames returns the names of the named parameter sets, in sorted order.
+func ParameterSetNames() []string {
+	return slices.Sorted(maps.Keys(namedParameterSets))
+}
+
+// ParseParameterSet parses a parameter set from a line of the form "overuse n d h' a k lg_w", where n
+// is the target security level in bytes.
+func ParseParameterSet(line string) (ParameterSet, error) {
+	names := []string{"overuse", "n", "d", "h'", "a", "k", "lg_w"}
+	split := strings.Split(line, " ")
+	if len(split) != len(names) {
+		return ParameterSet{}, fmt.Errorf("expected format: (%v); got %d fields", strings.Join(names, ", "), len(split))
+	}
+	values := make([]int, len(names))
+	for i, field := range split {
+		value, err := strconv.ParseInt(field, 10, 32)
+		if err != nil {
+			return ParameterSet{}, fmt.Errorf("could not parse %v from %q: %v", names[i], field, err)
+		}
+		values[i] = int(value)
+	}
+	return ParameterSet{
+		OveruseSecurityLevel: values[0],
+		TargetSecurityLevel:  values[1] * 8,
+		D:                    values[2],
+		HPrime:               values[3],
+		T:                    values[4],
+		K:                    values[5],
+		LgW:                  values[6],
+	}, nil
+}
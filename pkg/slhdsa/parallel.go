@@ -0,0 +1,85 @@
+package slhdsa
+
+// The calls to the hash functions on each chain of dependent calls in producing a signature. The calls
+// on a path must be made one after another, while the paths, and the same path for every other FORS
+// tree, WOTS+ chain and XMSS tree, can all be computed in parallel; the critical path is the most
+// costly of them.
+//
+// The WOTS+ signatures are taken from the chains of the signing leaf along the way, so they are not
+// on any path.
+func (p ParameterSet) SignaturePaths() []HashCalls {
+	return append(p.CachedSignaturePaths(),
+		// The message digest, which selects the XMSS trees, then a WOTS+ chain, its public key and the
+		// Merkle tree above it, for every layer of the hypertree at once
+		HashCalls{
+			PreHash: p.preHashes(),
+			PRFMsg:  1,
+			HMsg:    1,
+			PRF:     1,
+			F:       (1 << p.LgW) - 1,
+			TLen:    1,
+			H:       int64(p.HPrime),
+		})
+}
+
+// The calls to the hash functions on each chain of dependent calls in producing a signature if the
+// hypertree is cached (see SignaturePaths)
+func (p ParameterSet) CachedSignaturePaths() []HashCalls {
+	return []HashCalls{
+		// The message digest, then a FORS leaf, the Merkle tree above it and the FORS public key
+		{
+			PreHash: p.preHashes(),
+			PRFMsg:  1,
+			HMsg:    1,
+			PRF:     1,
+			F:       1,
+			H:       int64(p.T),
+			TK:      1,
+		},
+	}
+}
+
+// span returns the cost of the most costly of the paths, in the units of the parameter set's hash
+// family.
+func (p ParameterSet) span(paths []HashCalls) int64 {
+	costs := p.hashFamily().CallCosts(p)
+	var span int64
+	for _, path := range paths {
+		span = max(span, path.Cost(costs))
+	}
+	return span
+}
+
+// The cost of the critical path of producing a signature, in the units of the parameter set's hash
+// family: the time to sign with unlimited parallel hash units
+func (p ParameterSet) SignatureSpan() int64 {
+	return p.span(p.SignaturePaths())
+}
+
+// The cost of the critical path of producing a signature if the hypertree is cached, in the units of
+// the parameter set's hash family
+func (p ParameterSet) CachedSignatureSpan() int64 {
+	return p.span(p.CachedSignaturePaths())
+}
+
+// ParallelCost returns an upper bound on the time to do the given work with the given span (critical
+// path) on a number of parallel hash units, by Brent's theorem: a greedy schedule takes at most
+// span + (work - span) / units steps. A single unit (or fewer) takes the whole work.
+func ParallelCost(work, span int64, units int) int64 {
+	if units <= 1 {
+		return work
+	}
+	return span + (work-span+int64(units)-1)/int64(units)
+}
+
+// The cost of producing a signature with the given number of parallel hash units, in the units of the
+// parameter set's hash family (see ParallelCost)
+func (p ParameterSet) ParallelSignatureCost(units int) int64 {
+	return ParallelCost(p.SignatureCost(), p.SignatureSpan(), units)
+}
+
+// The cost of producing a signature if the hypertree is cached with the given number of parallel hash
+// units, in the units of the parameter set's hash family (see ParallelCost)
+func (p ParameterSet) ParallelCachedSignatureCost(units int) int64 {
+	return ParallelCost(p.CachedSignatureCost(), p.CachedSignatureSpan(), units)
+}
@@ -3,9 +3,14 @@ package slhdsa
 
 import (
 	"math"
+	"sort"
 )
 
 // ParameterSet contains all the values required to instantiate SLH-DSA.
+//
+// A ParameterSet is a plain value: its methods never modify it, so it may be copied freely and used
+// from multiple goroutines at once. Expensive derived values are memoized in a cache shared by all
+// parameter sets.
 type ParameterSet struct {
 	// The target security level in bits of the signature (e.g., 128 for Level 1)
 	TargetSecurityLevel int
@@ -21,18 +26,20 @@ type ParameterSet struct {
 	K int
 	// The 2^a = t private values within each FORS set
 	T int
-
-	// Cached values
-	securityLevelSignatureCount             *float64
-	securityLevel                           *float64
-	checkSecurityLevelSignatureCount        *float64
-	checkedSecurityLevel                    *bool
-	checkOveruseSecurityLevelSignatureCount *float64
-	checkedOveruseSecurityLevel             *bool
+	// The analysis used to compute the security level (defaults to DefaultSecurityModel if nil)
+	SecurityModel SecurityModel
+	// The instantiation of the hash functions, which determines the cost of each call to them
+	// (defaults to DefaultHashFamily if nil)
+	HashFamily HashFamily
+	// The length in bytes of the messages that are signed, which PRF_msg and H_msg process (defaults
+	// to DefaultMessageBytes if <= 0)
+	MessageBytes int
+	// Whether messages are pre-hashed (HashSLH-DSA), so that PRF_msg and H_msg only process the digest
+	PreHash bool
 }
 
 // The height of each XMSS key
-func (p *ParameterSet) HypertreeHeight() int {
+func (p ParameterSet) HypertreeHeight() int {
 	return p.HPrime * p.D
 }
 
@@ -42,20 +49,14 @@ func ceil(num, denom int) int {
 }
 
 // The length in bytes of the message digest
-func (p *ParameterSet) M() int {
+func (p ParameterSet) M() int {
 	return ceil(p.HypertreeHeight()-p.HPrime, 8) + ceil(p.HPrime, 8) + ceil(p.K*p.T, 8)
 }
 
-func (p *ParameterSet) SecurityLevel(m float64) float64 {
-	if p.securityLevelSignatureCount != nil && *p.securityLevelSignatureCount == m {
-		return *p.securityLevel
-	}
-
-	// Compute & cache
-	p.securityLevelSignatureCount = &m
-	result := p.ComputeSecurityLevel(m)
-	p.securityLevel = &result
-	return result
+// The security level of the parameter set for 2^m signatures
+// This is memoized across all parameter sets with the same K, T, hypertree height and security model.
+func (p ParameterSet) SecurityLevel(m float64) float64 {
+	return p.limitSecurityLevel(p.uncappedSecurityLevel(m))
 }
 
 // Adds two values in log2 representation
@@ -85,80 +86,15 @@ func addLogs(a, b float64) float64 {
 	return big + math.Log2(temp)
 }
 
-// Computes the exact security level of the parameter set for 2^m signatures
-// This is a Go translation of Scott Fluhrer's algorithm `compute_sec_level` from
-// https://github.com/sfluhrer/sphincs-param-set-search/blob/main/gamma.c
-func (p *ParameterSet) ComputeSecurityLevel(m float64) float64 {
-	// Lambda is the expected number of signatures per hypertree leaf at the specified number of signatures.
-	lambda := 0.0
-	if m > float64(p.HypertreeHeight()) {
-		lambda = math.Exp2(m - float64(p.HypertreeHeight()))
-	} else {
-		lambda = math.Pow(0.5, float64(p.HypertreeHeight())-m)
-	}
-	log_lambda := m - float64(p.HypertreeHeight())
-
-	// This is the probability that a probe does not hit a specific valid signature within a specific FORS tree
-	prob_not_get_single_hit := 1.0 - math.Pow(0.5, float64(p.T))
-
-	// This is the probability that no probes hit a specific valid signature in a specific FORS tree
-	// after g signatures have been generated from this FORS.
-	// This is updated as g is iterated.
-	prob_not_get_g_hit := 1.0
-
-	// a == lambda^g
-	log_a := 0.0
-
-	// the running sum
-	log_sum := 0.0
-
-	for g := 1; ; g++ {
-		// Update the variables that depend on g
-		log_a += float64(log_lambda)
-		log_a -= math.Log2(float64(g))
-		prob_not_get_g_hit *= prob_not_get_single_hit
-
-		// a is the probability that there will be precisely g valid signatures
-		// for this FORS (except for the constant e^{-\lambda} term; we'll
-		// account for that at the end)
-
-		// Compute b which is probability that a single forgery query will lie
-		// entirely in revealed FORS leaves (and thus will allow a signature
-		// of that forgery), assuming we have precisely g valid signatures for
-		// this FORS
-		log_b := 0.0
-		if prob_not_get_g_hit < 0.00001 {
-			// If prob_not_get_g_hit is sufficiently small, the subtraction
-			// will lose significant bits (or just result in 1)
-			// In this regime, the quadratic approximation, that is, the first
-			// two terms in the Taylor expansion, gives us a more accurate value
-			log_b = float64(-p.K) * (prob_not_get_g_hit/math.Log(2.0) +
-				prob_not_get_g_hit*prob_not_get_g_hit/(2*math.Log(2.0)))
-		} else {
-			log_b = float64(p.K) * math.Log2(1-prob_not_get_g_hit)
-		}
-
-		// Hence, the probability that this iteration adds to the sum is
-		// a*b, and since we're dealing with logs, log(ab) = log(a) + log(b)
-		if g == 1 {
-			// For the first iteration, the running sum is the first output
-			log_sum = log_a + log_b
-		} else {
-			// For latter iterations, add log(ab) to the running sum
-			log_sum = addLogs(log_sum, log_a+log_b)
-		}
-
-		// If the additional terms we're seeing is less than 2^{-20} of the
-		// sum, any further terms won't change the answer much - we might as
-		// well stop.  We test against log_a, as that is strictly decreasing
-		// and bounds the probability (as log_b < 0)
-		if g >= 10 && log_sum > 20+log_a {
-			break
-		}
-	}
+// Computes the security level of the parameter set for 2^m signatures using its security model,
+// limited to between 0 and the target security level
+func (p ParameterSet) ComputeSecurityLevel(m float64) float64 {
+	return p.limitSecurityLevel(p.securityModel().SecurityLevel(p, m))
+}
 
+// Limits a computed security level to between 0 and the target security level
+func (p ParameterSet) limitSecurityLevel(computed float64) float64 {
 	// We can't exceed the the target security level.
-	computed := lambda*math.Log2(math.E) - log_sum
 	if computed > float64(p.TargetSecurityLevel) {
 		return float64(p.TargetSecurityLevel)
 	}
@@ -171,59 +107,95 @@ func (p *ParameterSet) ComputeSecurityLevel(m float64) float64 {
 	return computed
 }
 
-func (p *ParameterSet) CheckSecurityLevel(m float64) bool {
-	if p.checkSecurityLevelSignatureCount != nil && *p.checkSecurityLevelSignatureCount == m {
-		return *p.checkedSecurityLevel
-	}
-
-	// Compute & cache
-	p.checkSecurityLevelSignatureCount = &m
-	result := p.checkSecurityLevel(m)
-	p.checkedSecurityLevel = &result
-	return result
-}
-
-func (p *ParameterSet) CheckOveruseSecurityLevel(m float64) bool {
-	if p.checkOveruseSecurityLevelSignatureCount != nil && *p.checkOveruseSecurityLevelSignatureCount == m {
-		return *p.checkedOveruseSecurityLevel
-	}
-
-	// Compute & cache
-	p.checkOveruseSecurityLevelSignatureCount = &m
-	result := p.checkOveruseSecurityLevel(m)
-	p.checkedOveruseSecurityLevel = &result
-	return result
-}
-
 // Checks if the parameter set meets its target security level for 2^m signatures
-func (p *ParameterSet) checkSecurityLevel(m float64) bool {
-	return p.ComputeSecurityLevel(m) >= float64(p.TargetSecurityLevel)
+func (p ParameterSet) CheckSecurityLevel(m float64) bool {
+	return p.SecurityLevel(m) >= float64(p.TargetSecurityLevel)
 }
 
 // Checks if the parameter set meets its target overuse security level for 2^m signatures
-func (p *ParameterSet) checkOveruseSecurityLevel(m float64) bool {
-	return p.ComputeSecurityLevel(m) >= float64(p.OveruseSecurityLevel)
+func (p ParameterSet) CheckOveruseSecurityLevel(m float64) bool {
+	return p.SecurityLevel(m) >= float64(p.OveruseSecurityLevel)
 }
 
+// The default precision of SignaturesAtLevel, in log_2 of the number of signatures
+const DefaultSignaturesPrecision = 0.01
+
 // The log_2 of the number of signatures that can be performed while retaining the security level
-// This is a Go translation of Scott Fluhrer's algorithm `compute_sigs_at_sec_level` from
+// This gives the same results as Scott Fluhrer's algorithm `compute_sigs_at_sec_level` from
 // https://github.com/sfluhrer/sphincs-param-set-search/blob/main/gamma.c
-func (p *ParameterSet) SignaturesAtLevel(target int) float64 {
-	// Scan for the number of signatures at a gross level (by integers)
-	lower := 0
-	for p.ComputeSecurityLevel(float64(lower+1)) >= float64(target) {
-		lower++
+func (p ParameterSet) SignaturesAtLevel(target int) float64 {
+	return p.SignaturesAtLevelWithPrecision(target, DefaultSignaturesPrecision)
+}
+
+// The log_2 of the number of signatures that can be performed while retaining the security level,
+// rounded to the nearest multiple of precision (defaults to DefaultSignaturesPrecision if <= 0)
+// This brackets the number of signatures between whole numbers and then bisects the bracket.
+func (p ParameterSet) SignaturesAtLevelWithPrecision(target int, precision float64) float64 {
+	if precision <= 0 {
+		precision = DefaultSignaturesPrecision
+	}
+	// The security level is never negative, so it is retained indefinitely
+	if target <= 0 {
+		return math.Inf(1)
+	}
+	return p.signaturesRetaining(func(m float64) bool {
+		return p.SecurityLevel(m) >= float64(target)
+	}, precision)
+}
+
+// The log_2 of the number of signatures up to which retained holds, rounded to the nearest multiple
+// of precision
+// The security level decreases as the number of signatures increases, so retained must hold up to
+// some number of signatures and not beyond it.
+func (p ParameterSet) signaturesRetaining(retained func(m float64) bool, precision float64) float64 {
+	// The number of signatures is usually close to 2^h, so start the bracket there.
+	lower := p.HypertreeHeight()
+	for lower > 0 && !retained(float64(lower)) {
+		lower--
+	}
+	upper := lower + 1
+	for retained(float64(upper)) {
+		upper++
+	}
+
+	// Find the first multiple of the precision that rounds to a number of signatures that does not
+	// retain the security level. Dividing by the scale (rather than multiplying by the precision)
+	// keeps decimal results such as 20.14 exact.
+	scale := 1 / precision
+	first := int(math.Floor(float64(lower) * scale))
+	last := int(math.Ceil(float64(upper) * scale))
+	n := first + sort.Search(last-first, func(i int) bool {
+		return !retained((float64(first+i) + 0.5) / scale)
+	})
+	return float64(n) / scale
+}
+
+// SecurityPoint is a point on the curve of the security level of a parameter set against the
+// number of signatures.
+type SecurityPoint struct {
+	// The log_2 of the number of signatures
+	Signatures float64
+	// The security level in bits after that many signatures
+	Security float64
+}
+
+// The security level of the parameter set for 2^m signatures, for each m from `from` to `to`
+// (inclusive) in increments of step
+func (p ParameterSet) SecurityCurve(from, to, step float64) []SecurityPoint {
+	if step <= 0 || to < from {
+		return nil
 	}
-	// Now scan by hundreds
-	fract := 0
-	for p.ComputeSecurityLevel(float64(lower)+float64(fract)/100.0+0.005) >= float64(target) {
-		fract++
+	count := int(math.Floor((to-from)/step+1e-9)) + 1
+	curve := make([]SecurityPoint, count)
+	for i := range curve {
+		m := from + float64(i)*step
+		curve[i] = SecurityPoint{Signatures: m, Security: p.SecurityLevel(m)}
 	}
-	return float64(lower) + (float64(fract) / 100.0)
+	return curve
 }
 
 // Returns the number of Winternitz digits used
-func (p *ParameterSet) WinternitzDigits() int {
+func (p ParameterSet) WinternitzDigits() int {
 	hash_d := ceil(p.TargetSecurityLevel, p.LgW)
 	w := 1 << p.LgW
 	max_sum := (w - 1) * hash_d
@@ -235,27 +207,27 @@ func (p *ParameterSet) WinternitzDigits() int {
 }
 
 // The size in bytes of each signature
-func (p *ParameterSet) SignatureSize() int {
+func (p ParameterSet) SignatureSize() int {
 	hash_size := (p.TargetSecurityLevel + 7) / 8
 
 	return hash_size * (1 + p.K*(p.T+1) + p.D*(p.WinternitzDigits()+p.HPrime))
 }
 
 // The number of hash operations required to produce a signature
-func (p *ParameterSet) SignatureHashes() int64 {
+func (p ParameterSet) SignatureHashes() int64 {
 	cost_ots := 1 + int64(p.WinternitzDigits())*(1<<p.LgW)
 	cost_hypertree := int64(p.D) * ((cost_ots+1)*(1<<p.HPrime) - 1)
 	cost_fors_tree := int64(3)*(1<<int64(p.T)) - 1
-	return 3 + cost_hypertree + int64(p.K)*cost_fors_tree
+	return 3 + cost_hypertree + int64(p.K)*cost_fors_tree + p.preHashes()
 }
 
 // The number of hash operations required to produce a signature if the hypertree is cached.
-func (p *ParameterSet) CachedSignatureHashes() int64 {
+func (p ParameterSet) CachedSignatureHashes() int64 {
 	cost_fors_tree := int64(3)*(1<<int64(p.T)) - 1
-	return 3 + int64(p.K)*cost_fors_tree
+	return 3 + int64(p.K)*cost_fors_tree + p.preHashes()
 }
 
 // The number of hash operations required to verify a signature
-func (p *ParameterSet) VerifyHashes() int64 {
-	return int64(1) + int64(p.K)*(int64(p.T)+1) + 1 + (int64(p.D) * (int64(p.WinternitzDigits())*(1<<int64(p.LgW))/2 + 1 + int64(p.HPrime)))
+func (p ParameterSet) VerifyHashes() int64 {
+	return int64(1) + int64(p.K)*(int64(p.T)+1) + 1 + (int64(p.D) * (int64(p.WinternitzDigits())*(1<<int64(p.LgW))/2 + 1 + int64(p.HPrime))) + p.preHashes()
 }
@@ -1,7 +1,11 @@
 package slhdsa
 
 import (
+	"maps"
 	"math"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -163,3 +167,585 @@ func TestParameterSetCalculations(t *testing.T) {
 		})
 	}
 }
+
+func TestSharedSecurityCache(t *testing.T) {
+	// These parameter sets differ only in how the hypertree is split and in the Winternitz parameter,
+	// so they must share a single cached security level.
+	a := ParameterSet{TargetSecurityLevel: 128, HPrime: 5, D: 4, T: 8, K: 23, LgW: 4}
+	b := ParameterSet{TargetSecurityLevel: 128, HPrime: 10, D: 2, T: 8, K: 23, LgW: 8}
+	const m = 20.5
+
+	want := a.ComputeSecurityLevel(m)
+	if got := a.SecurityLevel(m); got != want {
+		t.Errorf("SecurityLevel(%v) = %v, want %v", m, got, want)
+	}
+	if a.securityKey(m) != b.securityKey(m) {
+		t.Fatalf("securityKey(%v) = %+v and %+v, want equal keys", m, a.securityKey(m), b.securityKey(m))
+	}
+	got := sharedSecurityCache.securityLevel(b.securityKey(m), func() float64 {
+		t.Errorf("security level for %+v was recomputed", b)
+		return 0
+	})
+	if got != want {
+		t.Errorf("cached security level = %v, want %v", got, want)
+	}
+}
+
+func TestConcurrentSecurityLevel(t *testing.T) {
+	// Run with -race to check that parameter sets can be shared between goroutines.
+	p := ParameterSet{TargetSecurityLevel: 128, OveruseSecurityLevel: 112, HPrime: 9, D: 4, T: 14, K: 9, LgW: 8}
+	ms := []float64{20, 28, 30.5, 31, 36}
+	want := make([]float64, len(ms))
+	for i, m := range ms {
+		want[i] = p.ComputeSecurityLevel(m)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for i, m := range ms {
+				if got := p.SecurityLevel(m); got != want[i] {
+					t.Errorf("SecurityLevel(%v) = %v, want %v", m, got, want[i])
+				}
+				if got, want := p.CheckSecurityLevel(m), want[i] >= 128; got != want {
+					t.Errorf("CheckSecurityLevel(%v) = %v, want %v", m, got, want)
+				}
+				if got, want := p.CheckOveruseSecurityLevel(m), want[i] >= 112; got != want {
+					t.Errorf("CheckOveruseSecurityLevel(%v) = %v, want %v", m, got, want)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestSignaturesAtLevelWithPrecision(t *testing.T) {
+	p := ParameterSet{TargetSecurityLevel: 128, HPrime: 9, D: 4, T: 14, K: 9, LgW: 8}
+	for _, target := range []int{128, 112, 64} {
+		for _, precision := range []float64{1, 0.25, 0.01, 0.0001} {
+			got := p.SignaturesAtLevelWithPrecision(target, precision)
+			if n := got / precision; !closeEnough(n, math.Round(n)) {
+				t.Errorf("SignaturesAtLevelWithPrecision(%v, %v) = %v, want a multiple of the precision", target, precision, got)
+			}
+			// The result must be the nearest multiple of the precision to where the security level is lost.
+			if got > 0 && p.SecurityLevel(got-precision/2) < float64(target) {
+				t.Errorf("SecurityLevel(%v) < %v, want security retained below SignaturesAtLevelWithPrecision(%v, %v) = %v", got-precision/2, target, target, precision, got)
+			}
+			if p.SecurityLevel(got+precision/2) >= float64(target) {
+				t.Errorf("SecurityLevel(%v) >= %v, want security lost above SignaturesAtLevelWithPrecision(%v, %v) = %v", got+precision/2, target, target, precision, got)
+			}
+		}
+	}
+}
+
+func TestSecurityCurve(t *testing.T) {
+	p := ParameterSet{TargetSecurityLevel: 128, HPrime: 9, D: 4, T: 14, K: 9, LgW: 8}
+	curve := p.SecurityCurve(30, 32, 0.5)
+	if len(curve) != 5 {
+		t.Fatalf("SecurityCurve(30, 32, 0.5) has %d points, want 5", len(curve))
+	}
+	for i, point := range curve {
+		if want := 30 + 0.5*float64(i); point.Signatures != want {
+			t.Errorf("point %d has Signatures = %v, want %v", i, point.Signatures, want)
+		}
+		if want := p.ComputeSecurityLevel(point.Signatures); point.Security != want {
+			t.Errorf("point %d has Security = %v, want %v", i, point.Security, want)
+		}
+		if i > 0 && point.Security > curve[i-1].Security {
+			t.Errorf("security increased from %v to %v between 2^%v and 2^%v signatures", curve[i-1].Security, point.Security, curve[i-1].Signatures, point.Signatures)
+		}
+	}
+}
+
+func TestPreciseSecurityLevel(t *testing.T) {
+	for _, p := range []ParameterSet{
+		{TargetSecurityLevel: 128, HPrime: 5, D: 4, T: 8, K: 23, LgW: 4},
+		{TargetSecurityLevel: 128, HPrime: 15, D: 2, T: 24, K: 5, LgW: 8},
+		{TargetSecurityLevel: 256, HPrime: 21, D: 1, T: 25, K: 12, LgW: 2},
+	} {
+		h := float64(p.HypertreeHeight())
+		for _, m := range []float64{0, h, h + 4.5, h + 8} {
+			precise := p.PreciseSecurityLevel(m)
+			if width := precise.Upper - precise.Lower; width < 0 || width > 1e-9 {
+				t.Errorf("%+v: PreciseSecurityLevel(%v) = %+v, want an interval narrower than 1e-9", p, m, precise)
+			}
+			// The fast computation is an approximation, but it should be a good one.
+			fast := p.ComputeSecurityLevel(m)
+			widened := SecurityInterval{Lower: precise.Lower - 1e-6, Upper: precise.Upper + 1e-6}
+			if !widened.Contains(fast) {
+				t.Errorf("%+v: ComputeSecurityLevel(%v) = %v, want within 1e-6 of PreciseSecurityLevel(%v) = %+v", p, m, fast, m, precise)
+			}
+		}
+	}
+}
+
+func TestSecurityModels(t *testing.T) {
+	for _, name := range SecurityModelNames() {
+		model, err := SecurityModelByName(name)
+		if err != nil {
+			t.Fatalf("SecurityModelByName(%q) = %v", name, err)
+		}
+		if model.Name() != name {
+			t.Errorf("SecurityModelByName(%q).Name() = %q", name, model.Name())
+		}
+	}
+	if _, err := SecurityModelByName("unknown"); err == nil {
+		t.Errorf("SecurityModelByName(%q) succeeded, want error", "unknown")
+	}
+
+	fluhrer := ParameterSet{TargetSecurityLevel: 128, HPrime: 9, D: 4, T: 14, K: 9, LgW: 8}
+	sphincsPlus := fluhrer
+	sphincsPlus.SecurityModel = SPHINCSPlus{}
+	if fluhrer.securityKey(36) == sphincsPlus.securityKey(36) {
+		t.Errorf("securityKey(36) = %+v for both security models, want different keys", fluhrer.securityKey(36))
+	}
+	// The Fluhrer model approximates the binomial distribution of signatures per leaf of the
+	// SPHINCS+ bound with a Poisson distribution, which is very close for hypertrees this tall.
+	for _, m := range []float64{30, 36, 40} {
+		if got, want := sphincsPlus.SecurityLevel(m), fluhrer.SecurityLevel(m); math.Abs(got-want) > 0.001 {
+			t.Errorf("SPHINCS+ SecurityLevel(%v) = %v, want close to Fluhrer's %v", m, got, want)
+		}
+	}
+}
+
+func TestSecurityLevelBound(t *testing.T) {
+	for _, k := range []int{1, 6, 30} {
+		for _, tt := range []int{1, 8, 20} {
+			for _, logLambda := range []float64{10, 14, 18} {
+				p := ParameterSet{TargetSecurityLevel: 128, HPrime: 4, D: 1, K: k, T: tt, LgW: 4}
+				m := 4 + logLambda
+				level, bound := (Fluhrer{}).SecurityLevel(p, m), (Fluhrer{}).securityLevelBound(p, m)
+				if level > bound {
+					t.Errorf("K=%v, T=%v, m=%v: SecurityLevel() = %v, above securityLevelBound() = %v", k, tt, m, level, bound)
+				}
+			}
+		}
+	}
+	// The bound is tight enough to rule out a small hypertree
+	p := ParameterSet{TargetSecurityLevel: 128, HPrime: 1, D: 1, K: 6, T: 24, LgW: 4}
+	if bound := (Fluhrer{}).securityLevelBound(p, 30); bound >= 64 {
+		t.Errorf("securityLevelBound() = %v, want below 64", bound)
+	}
+	if !math.IsInf((Fluhrer{}).securityLevelBound(p, 5), 1) {
+		t.Errorf("securityLevelBound() with few signatures per leaf = %v, want +Inf", (Fluhrer{}).securityLevelBound(p, 5))
+	}
+}
+
+func TestSchemeSecurityLevel(t *testing.T) {
+	p := ParameterSet{TargetSecurityLevel: 128, HPrime: 22, D: 1, T: 24, K: 6, LgW: 2}
+
+	// With few signatures, the generic attacks on the three components secured by the hash length
+	// are the most effective, and the others barely contribute.
+	scheme := p.SchemeSecurityLevel(20)
+	if got, want := scheme.SecurityLevel, 128-math.Log2(3); got > want || got < want-0.001 {
+		t.Errorf("SchemeSecurityLevel(20).SecurityLevel = %v, want %v", got, want)
+	}
+	if got := scheme.Bottlenecks(); len(got) != 3 || got[0].Name != ComponentWOTS || got[1].Name != ComponentHypertree || got[2].Name != ComponentPRF {
+		t.Errorf("SchemeSecurityLevel(20).Bottlenecks() = %+v, want wots, hypertree and prf", got)
+	}
+
+	// With many signatures, FORS is the bottleneck.
+	scheme = p.SchemeSecurityLevel(30)
+	fors := p.ComputeSecurityLevel(30)
+	if got := scheme.SecurityLevel; got > fors || got < fors-0.001 {
+		t.Errorf("SchemeSecurityLevel(30).SecurityLevel = %v, want just under the FORS security level %v", got, fors)
+	}
+	if got := scheme.Bottlenecks(); len(got) != 1 || got[0].Name != ComponentFORS {
+		t.Errorf("SchemeSecurityLevel(30).Bottlenecks() = %+v, want fors", got)
+	}
+
+	// SLH-DSA-SHA2-128s has a 30-byte digest, of which the FORS indices use 14*12 bits, the tree index
+	// 54 bits and the leaf index 9 bits.
+	s, err := ParameterSetByName("SLH-DSA-SHA2-128s")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	for _, component := range s.SchemeSecurityLevel(20).Components {
+		if component.Name == ComponentDigest && component.SecurityLevel != 14*12+54+9-20 {
+			t.Errorf("SchemeSecurityLevel(20) digest security level = %v, want %v", component.SecurityLevel, 14*12+54+9-20)
+		}
+	}
+}
+
+func TestQuantumSecurity(t *testing.T) {
+	for _, tc := range []struct {
+		quantum float64
+		want    NISTCategory
+	}{
+		{0, NoNISTCategory},
+		{63.99, NoNISTCategory},
+		{64, NISTCategory1},
+		{95, NISTCategory1},
+		{96, NISTCategory3},
+		{128, NISTCategory5},
+		{200, NISTCategory5},
+	} {
+		if got := NISTCategoryOf(tc.quantum); got != tc.want {
+			t.Errorf("NISTCategoryOf(%v) = %v, want %v", tc.quantum, got, tc.want)
+		}
+	}
+
+	p := ParameterSet{TargetSecurityLevel: 192, HPrime: 9, D: 7, T: 14, K: 17, LgW: 4}
+	if got, want := p.QuantumSecurityLevel(20), 96.0; got != want {
+		t.Errorf("QuantumSecurityLevel(20) = %v, want %v", got, want)
+	}
+	if got, want := p.SchemeSecurityLevel(20).Quantum().SecurityLevel, 96-math.Log2(3); got > want || got < want-0.001 {
+		t.Errorf("SchemeSecurityLevel(20).Quantum().SecurityLevel = %v, want %v", got, want)
+	}
+}
+
+func TestFleetSecurityLevel(t *testing.T) {
+	p := ParameterSet{TargetSecurityLevel: 128, HPrime: 22, D: 1, T: 24, K: 6, LgW: 2}
+	for _, m := range []float64{20, 24, 30} {
+		if got, want := p.FleetSecurityLevel(m, 1), p.SecurityLevel(m); got != want {
+			t.Errorf("FleetSecurityLevel(%v, 1) = %v, want %v", m, got, want)
+		}
+		if got, want := p.FleetSecurityLevel(m, 64), math.Min(128, p.securityModel().SecurityLevel(p, m)-6); !closeEnough(got, want) {
+			t.Errorf("FleetSecurityLevel(%v, 64) = %v, want %v", m, got, want)
+		}
+	}
+	if got, want := p.FleetSignaturesAtLevel(128, 1), p.SignaturesAtLevel(128); got != want {
+		t.Errorf("FleetSignaturesAtLevel(128, 1) = %v, want %v", got, want)
+	}
+	// Each key makes fewer signatures, but there are more keys to make them.
+	perKey := p.FleetSignaturesAtLevel(128, 64) - 6
+	if perKey >= p.SignaturesAtLevel(128) || perKey+6 <= p.SignaturesAtLevel(128) {
+		t.Errorf("FleetSignaturesAtLevel(128, 64) = %v, want between %v and %v", perKey+6, p.SignaturesAtLevel(128), p.SignaturesAtLevel(128)+6)
+	}
+}
+
+func TestForgeryProbability(t *testing.T) {
+	p := ParameterSet{TargetSecurityLevel: 128, HPrime: 22, D: 1, T: 24, K: 6, LgW: 2}
+	const m = 30
+	security := p.SecurityLevel(m)
+
+	// With few queries, the probability is just the number of queries times the chance of each.
+	if got, want := p.ForgeryProbability(m, security-40), math.Exp2(-40); math.Abs(got-want) > want*1e-9 {
+		t.Errorf("ForgeryProbability(%v, %v) = %v, want %v", m, security-40, got, want)
+	}
+	// With 2^security queries, the attacker expects one forgery.
+	if got, want := p.ForgeryProbability(m, security), 1-1/math.E; math.Abs(got-want) > 1e-9 {
+		t.Errorf("ForgeryProbability(%v, %v) = %v, want %v", m, security, got, want)
+	}
+	if got := p.ForgeryProbability(m, security+10); got < 0.999 || got > 1 {
+		t.Errorf("ForgeryProbability(%v, %v) = %v, want nearly 1", m, security+10, got)
+	}
+}
+
+func TestSecurityFloor(t *testing.T) {
+	floor, err := ParseSecurityFloor(strings.NewReader("sigs,security\n20,128\n30, 112\n40,96\n"))
+	if err != nil {
+		t.Fatalf("ParseSecurityFloor() = %v", err)
+	}
+	for _, tc := range []struct {
+		m    float64
+		want float64
+	}{
+		{0, 128},
+		{20, 128},
+		{25, 120},
+		{35, 104},
+		{40, 96},
+		{50, 96},
+	} {
+		if got := floor.At(tc.m); got != tc.want {
+			t.Errorf("At(%v) = %v, want %v", tc.m, got, tc.want)
+		}
+	}
+
+	p := ParameterSet{TargetSecurityLevel: 128, HPrime: 9, D: 4, T: 14, K: 9, LgW: 8}
+	want := math.Min(p.SecurityLevel(30)-112, p.SecurityLevel(40)-96)
+	if got := p.FloorMargin(floor); got != want {
+		t.Errorf("FloorMargin() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"", "20,128\n10,112\n", "20,128\nx,112\n", "20\n"} {
+		if _, err := ParseSecurityFloor(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseSecurityFloor(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestParameterSetByName(t *testing.T) {
+	p, err := ParameterSetByName("slh-dsa-sha2-128s")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	// SLH-DSA-128s has 2^64 signatures at the target security level
+	if sigs := p.SignaturesAtLevel(p.TargetSecurityLevel); sigs < 64 {
+		t.Errorf("SignaturesAtLevel(%v) = %v, want at least 64", p.TargetSecurityLevel, sigs)
+	}
+	for _, name := range ParameterSetNames() {
+		if _, err := ParameterSetByName(name); err != nil {
+			t.Errorf("ParameterSetByName(%q) = %v", name, err)
+		}
+	}
+	if _, err := ParameterSetByName("SLH-DSA-SHA2-64s"); err == nil {
+		t.Errorf("ParameterSetByName() = nil, want error")
+	}
+}
+
+func TestParseParameterSet(t *testing.T) {
+	got, err := ParseParameterSet("112 16 1 22 24 6 2")
+	if err != nil {
+		t.Fatalf("ParseParameterSet() = %v", err)
+	}
+	if want, _ := ParameterSetByName("rls128cs1"); got != want {
+		t.Errorf("ParseParameterSet() = %+v, want %+v", got, want)
+	}
+	for _, line := range []string{"16 1 22 24 6 2", "112 16 1 22 24 6 two"} {
+		if _, err := ParseParameterSet(line); err == nil {
+			t.Errorf("ParseParameterSet(%q) = nil, want error", line)
+		}
+	}
+}
+
+func TestHashFamilies(t *testing.T) {
+	for _, name := range ParameterSetNames() {
+		p, err := ParameterSetByName(name)
+		if err != nil {
+			t.Fatalf("ParameterSetByName() = %v", err)
+		}
+		// The calls to each hash function add up to the abstract hash counts
+		if got, want := p.SignatureHashCalls().Total(), p.SignatureHashes(); got != want {
+			t.Errorf("%v: SignatureHashCalls().Total() = %v, want %v", name, got, want)
+		}
+		if got, want := p.CachedSignatureHashCalls().Total(), p.CachedSignatureHashes(); got != want {
+			t.Errorf("%v: CachedSignatureHashCalls().Total() = %v, want %v", name, got, want)
+		}
+		if got, want := p.VerifyHashCalls().Total(), p.VerifyHashes(); got != want {
+			t.Errorf("%v: VerifyHashCalls().Total() = %v, want %v", name, got, want)
+		}
+		if got, want := p.SignatureCost(), p.SignatureHashes(); got != want {
+			t.Errorf("%v: SignatureCost() = %v, want %v", name, got, want)
+		}
+
+		// Every call takes at least one compression or permutation
+		for _, family := range HashFamilyNames() {
+			p.HashFamily, err = HashFamilyByName(family)
+			if err != nil {
+				t.Fatalf("HashFamilyByName() = %v", err)
+			}
+			if p.SignatureCost() < p.SignatureHashes() || p.CachedSignatureCost() < p.CachedSignatureHashes() || p.VerifyCost() < p.VerifyHashes() {
+				t.Errorf("%v: %v costs (%v, %v, %v) are less than the hash counts", name, family, p.SignatureCost(), p.CachedSignatureCost(), p.VerifyCost())
+			}
+
+			// The costs of each primitive add up to the total cost
+			var total int64
+			for _, count := range p.PrimitiveCounts(p.SignatureHashCalls()) {
+				total += count
+			}
+			if total != p.SignatureCost() {
+				t.Errorf("%v: %v primitive counts add up to %v, want %v", name, family, total, p.SignatureCost())
+			}
+		}
+	}
+
+	p, err := ParameterSetByName("SLH-DSA-SHA2-128s")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	// T_k hashes 22 bytes of address and 14 roots of 16 bytes, which takes 4 SHA-256 blocks
+	if got := (SHA2{}).CallCosts(p); got.F != 1 || got.H != 1 || got.TK != 4 || got.TLen != 10 {
+		t.Errorf("SHA2.CallCosts() = %+v", got)
+	}
+	// T_k absorbs 16+32+14*16 bytes, which takes 3 Keccak-f permutations
+	if got := (SHAKE{}).CallCosts(p); got.F != 1 || got.H != 1 || got.TK != 3 || got.TLen != 5 {
+		t.Errorf("SHAKE.CallCosts() = %+v", got)
+	}
+
+	// A 1 MiB message takes 16384 more SHA-256 blocks for PRF_msg, and 16383 more for H_msg
+	p.HashFamily = SHA2{}
+	short := p
+	p.MessageBytes = 1 << 20
+	if got, want := p.SignatureCost()-short.SignatureCost(), int64(16384+16383); got != want {
+		t.Errorf("SHA2 SignatureCost() grows by %v for a 1 MiB message, want %v", got, want)
+	}
+	if got, want := p.VerifyCost()-short.VerifyCost(), int64(16383); got != want {
+		t.Errorf("SHA2 VerifyCost() grows by %v for a 1 MiB message, want %v", got, want)
+	}
+	// Pre-hashing processes it only once, in 16385 blocks, and is an extra call to a hash function; the
+	// OID and digest take PRF_msg one block more than the default message
+	p.PreHash = true
+	if got, want := p.SignatureCost()-short.SignatureCost(), int64(16385+1); got != want {
+		t.Errorf("SHA2 SignatureCost() grows by %v for a pre-hashed 1 MiB message, want %v", got, want)
+	}
+	if got, want := p.VerifyCost()-short.VerifyCost(), int64(16385); got != want {
+		t.Errorf("SHA2 VerifyCost() grows by %v for a pre-hashed 1 MiB message, want %v", got, want)
+	}
+	if got, want := p.SignatureHashCalls().Total(), p.SignatureHashes(); got != want || got != short.SignatureHashes()+1 {
+		t.Errorf("SignatureHashCalls().Total() = %v, want %v, one more than %v", got, want, short.SignatureHashes())
+	}
+	if got, want := p.VerifyHashCalls().Total(), p.VerifyHashes(); got != want || got != short.VerifyHashes()+1 {
+		t.Errorf("VerifyHashCalls().Total() = %v, want %v, one more than %v", got, want, short.VerifyHashes())
+	}
+	// Abstractly, each 64 bytes of the message is a hash, for PRF_msg and H_msg or for the pre-hash
+	p.HashFamily = AbstractHashes{}
+	short.HashFamily = AbstractHashes{}
+	p.PreHash = false
+	if got, want := p.SignatureCost()-short.SignatureCost(), int64(2*16383); got != want {
+		t.Errorf("abstract SignatureCost() grows by %v for a 1 MiB message, want %v", got, want)
+	}
+	p.PreHash = true
+	if got, want := p.VerifyCost()-short.VerifyCost(), int64(16384); got != want {
+		t.Errorf("abstract VerifyCost() grows by %v for a pre-hashed 1 MiB message, want %v", got, want)
+	}
+	// SHAKE128 absorbs 168 bytes per permutation
+	p.HashFamily = SHAKE{}
+	if got, want := (SHAKE{}).CallCosts(p).PreHash, int64(6242); got != want {
+		t.Errorf("SHAKE.CallCosts().PreHash = %v, want %v", got, want)
+	}
+
+	if _, err := HashFamilyByName("md5"); err == nil {
+		t.Errorf("HashFamilyByName() = nil, want error")
+	}
+}
+
+func TestParallelSignatureCost(t *testing.T) {
+	p, err := ParameterSetByName("SLH-DSA-SHA2-128s")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	// PRF_msg, H_msg, PRF, 15 steps of a chain, T_len and 9 levels of the XMSS tree
+	if got := p.SignatureSpan(); got != 28 {
+		t.Errorf("SignatureSpan() = %v, want 28", got)
+	}
+	// PRF_msg, H_msg, PRF, F, 12 levels of the FORS tree and T_k
+	if got := p.CachedSignatureSpan(); got != 17 {
+		t.Errorf("CachedSignatureSpan() = %v, want 17", got)
+	}
+
+	if got, want := p.ParallelSignatureCost(1), p.SignatureCost(); got != want {
+		t.Errorf("ParallelSignatureCost(1) = %v, want %v", got, want)
+	}
+	if got, want := p.ParallelCachedSignatureCost(0), p.CachedSignatureCost(); got != want {
+		t.Errorf("ParallelCachedSignatureCost(0) = %v, want %v", got, want)
+	}
+	// More units are never slower, but never faster than the span
+	last := p.SignatureCost()
+	for _, units := range []int{2, 4, 32, 1 << 20, 1 << 40} {
+		got := p.ParallelSignatureCost(units)
+		if got > last || got < p.SignatureSpan() {
+			t.Errorf("ParallelSignatureCost(%v) = %v, want between %v and %v", units, got, p.SignatureSpan(), last)
+		}
+		last = got
+	}
+	if got, want := p.ParallelSignatureCost(1<<40), p.SignatureSpan()+1; got != want {
+		t.Errorf("ParallelSignatureCost(2^40) = %v, want %v", got, want)
+	}
+
+	// With SHA2, PRF_msg and H_msg take 4 compressions each and T_len takes 10
+	p.HashFamily = SHA2{}
+	if got := p.SignatureSpan(); got != 43 {
+		t.Errorf("SHA2 SignatureSpan() = %v, want 43", got)
+	}
+}
+
+func TestHypertreeCache(t *testing.T) {
+	p, err := ParameterSetByName("SLH-DSA-SHA2-128s")
+	if err != nil {
+		t.Fatalf("ParameterSetByName() = %v", err)
+	}
+	// No cache and a full cache are the uncached and cached costs
+	if got, want := (HypertreeCache{}).SignatureHashes(p), p.SignatureHashes(); got != want {
+		t.Errorf("SignatureHashes() with no cache = %v, want %v", got, want)
+	}
+	if got := (HypertreeCache{}).Bytes(p); got != 0 {
+		t.Errorf("Bytes() with no cache = %v, want 0", got)
+	}
+	if got, want := (HypertreeCache{Layers: p.D}).SignatureHashes(p), p.CachedSignatureHashes(); got != want {
+		t.Errorf("SignatureHashes() with every layer cached = %v, want %v", got, want)
+	}
+	if got, want := (HypertreeCache{Layers: p.D}).ParallelSignatureCost(p, 8), p.ParallelCachedSignatureCost(8); got != want {
+		t.Errorf("ParallelSignatureCost() with every layer cached = %v, want %v", got, want)
+	}
+
+	// The top XMSS tree has 1022 nodes below its root, and 512 WOTS+ signatures of 35 values
+	top := HypertreeCache{Layers: 1}
+	if got, want := top.Bytes(p), float64(16*(1022+512*35)); got != want {
+		t.Errorf("Bytes() with the top layer cached = %v, want %v", got, want)
+	}
+	// The bottom layer keeps all 16 values of each WOTS+ chain, since its signatures depend on the
+	// message
+	bottomTrees := math.Exp2(float64((p.D - 1) * p.HPrime))
+	if got, want := (HypertreeCache{Layers: p.D}).Bytes(p), (HypertreeCache{Layers: p.D - 1}).Bytes(p)+bottomTrees*16*(1022+512*35*16); got != want {
+		t.Errorf("Bytes() with every layer cached = %v, want %v", got, want)
+	}
+	// Caching the top 3 levels of every other XMSS tree leaves subtrees of 64 leaves
+	partial := HypertreeCache{Layers: 1, Levels: 3}
+	if got, want := partial.SignatureHashes(p), p.CachedSignatureHashes()+6*(64*(1+35*16)+63); got != want {
+		t.Errorf("SignatureHashes() with the top layer and 3 levels cached = %v, want %v", got, want)
+	}
+
+	// Caching more layers, or more levels of the other layers, takes more space and fewer hashes
+	for layers := range p.D {
+		cache := HypertreeCache{Layers: layers}
+		for _, next := range []HypertreeCache{{Layers: layers + 1}, {Layers: layers, Levels: 1}} {
+			if cache.SignatureHashes(p) <= next.SignatureHashes(p) || cache.Bytes(p) >= next.Bytes(p) {
+				t.Errorf("%+v and %+v: SignatureHashes() = %v and %v, Bytes() = %v and %v", cache, next,
+					cache.SignatureHashes(p), next.SignatureHashes(p), cache.Bytes(p), next.Bytes(p))
+			}
+		}
+	}
+}
+
+func TestVerifyHashDistribution(t *testing.T) {
+	// With 8-bit messages and w = 4, there are 4 message digits and 2 checksum digits (up to 12)
+	p := ParameterSet{TargetSecurityLevel: 8, HPrime: 1, D: 1, LgW: 2, K: 1, T: 1}
+	counts := make(map[int64]int)
+	for message := range 256 {
+		var checksum, steps int
+		for i := range 4 {
+			checksum += 3 - (message>>(2*i))&3
+		}
+		steps = checksum
+		for i := range 2 {
+			steps += 3 - (checksum>>(2*i))&3
+		}
+		counts[int64(steps)]++
+	}
+	// Two layers add up every pair of messages
+	pairs := make(map[int64]int)
+	for a, countA := range counts {
+		for b, countB := range counts {
+			pairs[a+b] += countA * countB
+		}
+	}
+	for _, test := range []struct {
+		d      int
+		counts map[int64]int
+		total  int
+	}{
+		{1, counts, 256},
+		{2, pairs, 256 * 256},
+	} {
+		p.D = test.d
+		steps := p.verifyChainSteps()
+		if want := slices.Min(slices.Collect(maps.Keys(test.counts))); steps.best != want {
+			t.Errorf("d=%v: best = %v, want %v", test.d, steps.best, want)
+		}
+		if want := slices.Max(slices.Collect(maps.Keys(test.counts))); steps.worst != want {
+			t.Errorf("d=%v: worst = %v, want %v", test.d, steps.worst, want)
+		}
+		for i, probability := range steps.pmf {
+			if want := float64(test.counts[steps.best+int64(i)]) / float64(test.total); math.Abs(probability-want) > 1e-12 {
+				t.Errorf("d=%v: probability of %v steps = %v, want %v", test.d, steps.best+int64(i), probability, want)
+			}
+		}
+	}
+
+	for _, name := range ParameterSetNames() {
+		p, err := ParameterSetByName(name)
+		if err != nil {
+			t.Fatalf("ParameterSetByName() = %v", err)
+		}
+		best, p50, p99, worst := p.BestVerifyHashes(), p.VerifyHashCallsAt(50).Total(), p.VerifyHashCallsAt(99).Total(), p.WorstVerifyHashes()
+		if best >= p50 || p50 >= p99 || p99 >= worst {
+			t.Errorf("%v: best, p50, p99 and worst verify hashes = %v, %v, %v, %v", name, best, p50, p99, worst)
+		}
+		// The average is close to the median
+		if mean := p.MeanVerifyHashes(); math.Abs(mean-float64(p50)) > 0.02*mean {
+			t.Errorf("%v: MeanVerifyHashes() = %v, p50 = %v", name, mean, p50)
+		}
+	}
+}
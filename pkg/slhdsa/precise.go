@@ -0,0 +1,184 @@
+package slhdsa
+
+import (
+	"math"
+	"math/big"
+)
+
+// The number of bits of precision used by PreciseSecurityLevel
+const preciseBits = 256
+
+// log_2(e) to 60 significant digits
+const log2E = "1.44269504088896340735992468100189213742664595415298593413545"
+
+// The maximum error of log_2(e) as written above
+const log2EError = 1e-59
+
+// The maximum error in computing log_2 of a mantissa in [0.5, 1) with math.Log2, including the
+// error in first rounding the mantissa to a float64
+var log2MantissaError = math.Ldexp(1, -48)
+
+// SecurityInterval bounds the security level (in bits) of a parameter set.
+type SecurityInterval struct {
+	// The security level is at least this
+	Lower float64
+	// The security level is at most this
+	Upper float64
+}
+
+// Contains checks whether the given security level is within the interval.
+func (i SecurityInterval) Contains(security float64) bool {
+	return i.Lower <= security && security <= i.Upper
+}
+
+// interval is a pair of arbitrary-precision bounds, the lower of which is always rounded down and
+// the upper of which is always rounded up.
+type interval struct {
+	lo, hi *big.Float
+}
+
+func newInterval() interval {
+	return interval{
+		lo: new(big.Float).SetPrec(preciseBits).SetMode(big.ToNegativeInf),
+		hi: new(big.Float).SetPrec(preciseBits).SetMode(big.ToPositiveInf),
+	}
+}
+
+// exactInterval returns an interval containing just x, which must be exactly representable.
+func exactInterval(x float64) interval {
+	i := newInterval()
+	i.lo.SetFloat64(x)
+	i.hi.SetFloat64(x)
+	return i
+}
+
+// roundDown returns the largest float64 not above x.
+func roundDown(x *big.Float) float64 {
+	f, acc := x.Float64()
+	if acc == big.Above {
+		f = math.Nextafter(f, math.Inf(-1))
+	}
+	return f
+}
+
+// roundUp returns the smallest float64 not below x.
+func roundUp(x *big.Float) float64 {
+	f, acc := x.Float64()
+	if acc == big.Below {
+		f = math.Nextafter(f, math.Inf(1))
+	}
+	return f
+}
+
+// log2Interval bounds log_2(x) for x > 0.
+func log2Interval(x *big.Float) interval {
+	mantissa := new(big.Float)
+	exp := x.MantExp(mantissa)
+	m, _ := mantissa.Float64()
+	l := math.Log2(m)
+
+	i := newInterval()
+	i.lo.SetInt64(int64(exp))
+	i.lo.Add(i.lo, big.NewFloat(l-log2MantissaError))
+	i.hi.SetInt64(int64(exp))
+	i.hi.Add(i.hi, big.NewFloat(l+log2MantissaError))
+	return i
+}
+
+// PreciseSecurityLevel bounds the security level of the parameter set for 2^m signatures.
+//
+// This evaluates the same formula as the Fluhrer security model (regardless of the parameter set's
+// SecurityModel), but with arbitrary-precision interval arithmetic instead of float64
+// approximations: every rounding is directed so that the bounds stay valid, and the series is only
+// truncated once a bound on all of the remaining terms is negligible. The resulting interval
+// contains the exact value of the formula.
+func (p ParameterSet) PreciseSecurityLevel(m float64) SecurityInterval {
+	// Lambda is the expected number of signatures per hypertree leaf. math.Exp2 is accurate to
+	// within a unit in the last place, so allow for two.
+	l := math.Exp2(m - float64(p.HypertreeHeight()))
+	lambda := newInterval()
+	lambda.lo.SetFloat64(math.Nextafter(math.Nextafter(l, 0), 0))
+	lambda.hi.SetFloat64(math.Nextafter(math.Nextafter(l, math.Inf(1)), math.Inf(1)))
+
+	// The probability that a probe does not hit a specific valid signature within a specific FORS
+	// tree, 1 - 2^-a, is exactly representable.
+	notHit := new(big.Float).SetPrec(preciseBits).SetMantExp(big.NewFloat(-1), -p.T)
+	notHit.Add(notHit, big.NewFloat(1))
+
+	// The sum over g of lambda^g/g! (the probability that there are precisely g valid signatures
+	// for a FORS, except for the constant e^{-lambda} term) times the probability that a single
+	// forgery query lies entirely in the revealed FORS leaves of those g signatures
+	sum := exactInterval(0)
+	// lambda^g/g!
+	poisson := exactInterval(1)
+	// The probability that no probes hit a specific valid signature in a specific FORS tree after g
+	// signatures, (1 - 2^-a)^g
+	notGHit := exactInterval(1)
+	// The remaining terms of the sum are bounded by this
+	tail := newInterval().hi
+	one := big.NewFloat(1)
+
+	for g := int64(1); ; g++ {
+		bigG := new(big.Float).SetInt64(g)
+		poisson.lo.Mul(poisson.lo, lambda.lo)
+		poisson.lo.Quo(poisson.lo, bigG)
+		poisson.hi.Mul(poisson.hi, lambda.hi)
+		poisson.hi.Quo(poisson.hi, bigG)
+		notGHit.lo.Mul(notGHit.lo, notHit)
+		notGHit.hi.Mul(notGHit.hi, notHit)
+
+		// The probability of hitting all K trees, (1 - (1 - 2^-a)^g)^K
+		hitOne := newInterval()
+		hitOne.lo.Sub(one, notGHit.hi)
+		hitOne.hi.Sub(one, notGHit.lo)
+		term := exactInterval(1)
+		for range p.K {
+			term.lo.Mul(term.lo, hitOne.lo)
+			term.hi.Mul(term.hi, hitOne.hi)
+		}
+
+		term.lo.Mul(term.lo, poisson.lo)
+		term.hi.Mul(term.hi, poisson.hi)
+		sum.lo.Add(sum.lo, term.lo)
+		sum.hi.Add(sum.hi, term.hi)
+
+		// Each remaining term is at most lambda^g/g!, so once g+2 > lambda their sum is at most
+		// lambda^{g+1}/(g+1)! * 1/(1 - lambda/(g+2)).
+		next := new(big.Float).SetInt64(g + 2)
+		if next.Cmp(lambda.hi) <= 0 {
+			continue
+		}
+		tail.Mul(poisson.hi, lambda.hi)
+		tail.Quo(tail, new(big.Float).SetInt64(g+1))
+		tail.Mul(tail, next)
+		ratio := newInterval().lo.Sub(next, lambda.hi)
+		tail.Quo(tail, ratio)
+
+		// Stop once the remaining terms are well beyond the precision of a float64.
+		if sum.lo.Sign() > 0 && new(big.Float).SetMantExp(tail, 64).Cmp(sum.lo) <= 0 {
+			break
+		}
+	}
+	sum.hi.Add(sum.hi, tail)
+
+	// The security level is -log_2(e^{-lambda} * sum) = lambda * log_2(e) - log_2(sum).
+	e := newInterval()
+	e.lo.SetString(log2E)
+	e.lo.Sub(e.lo, big.NewFloat(log2EError))
+	e.hi.SetString(log2E)
+	e.hi.Add(e.hi, big.NewFloat(log2EError))
+	security := newInterval()
+	security.lo.Mul(lambda.lo, e.lo)
+	security.lo.Sub(security.lo, log2Interval(sum.hi).hi)
+	security.hi.Mul(lambda.hi, e.hi)
+	security.hi.Sub(security.hi, log2Interval(sum.lo).lo)
+
+	// As with ComputeSecurityLevel, the result is limited to between 0 and the target security level.
+	clamp := func(x float64) float64 {
+		return math.Max(0, math.Min(x, float64(p.TargetSecurityLevel)))
+	}
+	return SecurityInterval{
+		Lower: clamp(roundDown(security.lo)),
+		Upper: clamp(roundUp(security.hi)),
+	}
+}
@@ -0,0 +1,78 @@
+package slhdsa
+
+import (
+	"fmt"
+)
+
+// Every attack considered here is a search for a preimage (of a hash value, or of a digest that
+// lands in revealed FORS leaves) or for a key, so a quantum attacker gains at most Grover's square
+// root speedup: an attack costing 2^s classically costs 2^{s/2} quantumly. SLH-DSA does not rely on
+// collision resistance, so there are no collision attacks to account for.
+
+// QuantumSecurity converts a classical security level (in bits) into a quantum one.
+func QuantumSecurity(classical float64) float64 {
+	return classical / 2
+}
+
+// ClassicalSecurity converts a quantum security level (in bits) into a classical one.
+func ClassicalSecurity(quantum float64) float64 {
+	return 2 * quantum
+}
+
+// The quantum security level of the parameter set for 2^m signatures
+func (p ParameterSet) QuantumSecurityLevel(m float64) float64 {
+	return QuantumSecurity(p.SecurityLevel(m))
+}
+
+// Quantum returns the security level of the whole scheme against a quantum attacker.
+func (s SchemeSecurity) Quantum() SchemeSecurity {
+	components := make([]SecurityComponent, len(s.Components))
+	for i, component := range s.Components {
+		components[i] = SecurityComponent{Name: component.Name, SecurityLevel: QuantumSecurity(component.SecurityLevel)}
+	}
+	return combineComponents(components)
+}
+
+// NISTCategory is one of NIST's post-quantum security strength categories.
+type NISTCategory int
+
+// The NIST security categories that are defined by key search on a block cipher
+const (
+	// Below category 1
+	NoNISTCategory NISTCategory = 0
+	// At least as hard to break as AES-128
+	NISTCategory1 NISTCategory = 1
+	// At least as hard to break as AES-192
+	NISTCategory3 NISTCategory = 3
+	// At least as hard to break as AES-256
+	NISTCategory5 NISTCategory = 5
+)
+
+// The quantum security level (in bits) of key search on AES for each category, using Grover's
+// algorithm, in descending order
+var nistCategoryLevels = []struct {
+	category NISTCategory
+	quantum  float64
+}{
+	{NISTCategory5, 128},
+	{NISTCategory3, 96},
+	{NISTCategory1, 64},
+}
+
+// NISTCategoryOf returns the highest NIST security category met by the given quantum security level
+// (in bits).
+func NISTCategoryOf(quantum float64) NISTCategory {
+	for _, level := range nistCategoryLevels {
+		if quantum >= level.quantum {
+			return level.category
+		}
+	}
+	return NoNISTCategory
+}
+
+func (c NISTCategory) String() string {
+	if c == NoNISTCategory {
+		return "none"
+	}
+	return fmt.Sprintf("%d", int(c))
+}
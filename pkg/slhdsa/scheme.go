@@ -0,0 +1,96 @@
+package slhdsa
+
+import (
+	"math"
+)
+
+// The components of SLH-DSA that are attacked separately by SchemeSecurityLevel
+const (
+	// Forging a FORS signature from the FORS leaves revealed by previous signatures
+	ComponentFORS = "fors"
+	// Finding a message whose digest is identical to that of a signed message
+	ComponentDigest = "digest"
+	// Finding a preimage of a WOTS+ chain value
+	ComponentWOTS = "wots"
+	// Finding a preimage of a node in one of the Merkle trees of the hypertree
+	ComponentHypertree = "hypertree"
+	// Recovering the secret seeds behind PRF and PRF_msg
+	ComponentPRF = "prf"
+)
+
+// SecurityComponent is the security level (in bits) of a single component of SLH-DSA.
+type SecurityComponent struct {
+	Name          string
+	SecurityLevel float64
+}
+
+// SchemeSecurity is the security level of SLH-DSA as a whole, accounting for attacks on each of its
+// components.
+type SchemeSecurity struct {
+	// The security level of each component
+	Components []SecurityComponent
+	// The combined security level: a forger succeeds by breaking any one of the components, so the
+	// probabilities of breaking each of them add up.
+	SecurityLevel float64
+}
+
+// Bottlenecks returns the components with the lowest security level.
+func (s SchemeSecurity) Bottlenecks() []SecurityComponent {
+	var bottlenecks []SecurityComponent
+	for _, component := range s.Components {
+		switch {
+		case len(bottlenecks) == 0 || component.SecurityLevel < bottlenecks[0].SecurityLevel:
+			bottlenecks = []SecurityComponent{component}
+		case component.SecurityLevel == bottlenecks[0].SecurityLevel:
+			bottlenecks = append(bottlenecks, component)
+		}
+	}
+	return bottlenecks
+}
+
+// Estimates the security level of the whole scheme for 2^m signatures
+// Unlike SecurityLevel, which is just the security of FORS against forgeries, this includes the
+// generic attacks against every component of the scheme:
+//   - The digest: a message whose digest matches one of the 2^m signed digests in every bit that
+//     selects the FORS leaves and the hypertree leaf takes 2^(digestBits - m) hashes to find.
+//   - WOTS+ and the hypertree: a forgery needs a second preimage of F, or of H or T_l, on an n-byte
+//     value. Every call is tweaked with a unique address, so the many revealed values cannot be
+//     attacked at once, and each takes 2^(8n) hashes to find.
+//   - PRF: SK.seed and SK.prf are n bytes, so guessing either takes 2^(8n) hashes.
+func (p ParameterSet) SchemeSecurityLevel(m float64) SchemeSecurity {
+	n := float64(8 * ceil(p.TargetSecurityLevel, 8))
+	components := []SecurityComponent{
+		// Unlike ComputeSecurityLevel, this is not limited to the target security level, so that the
+		// other components can be compared against it.
+		{ComponentFORS, math.Max(0, p.uncappedSecurityLevel(m))},
+		{ComponentDigest, math.Max(0, float64(p.digestBits())-m)},
+		{ComponentWOTS, n},
+		{ComponentHypertree, n},
+		{ComponentPRF, n},
+	}
+	return combineComponents(components)
+}
+
+// digestBits returns the number of bits of the M()-byte message digest that are used. FIPS 205
+// (Algorithm 19) splits the digest into byte strings for the FORS indices, the tree index and the leaf
+// index, and discards the bits of each beyond the k*a, h - h' and h' bits it needs.
+func (p ParameterSet) digestBits() int {
+	discarded := 0
+	for _, bits := range []int{p.K * p.T, p.HypertreeHeight() - p.HPrime, p.HPrime} {
+		discarded += 8*ceil(bits, 8) - bits
+	}
+	return 8*p.M() - discarded
+}
+
+// combineComponents computes the security level of the whole scheme from that of its components.
+func combineComponents(components []SecurityComponent) SchemeSecurity {
+	// Add up the probabilities of breaking each component
+	logProbability := math.Inf(-1)
+	for _, component := range components {
+		logProbability = addLogs(logProbability, -component.SecurityLevel)
+	}
+	return SchemeSecurity{
+		Components:    components,
+		SecurityLevel: math.Max(0, -logProbability),
+	}
+}
@@ -0,0 +1,228 @@
+package slhdsa
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// chainSteps is the distribution of the number of steps of the WOTS+ chains (calls to F) that a
+// verifier computes for the WOTS+ signatures of a signature, over messages. It assumes that every
+// digit of each message signed in the hypertree is uniformly random, which holds for the FORS public
+// key and the XMSS roots.
+type chainSteps struct {
+	// The fewest and most steps
+	best, worst int64
+	// The average number of steps
+	mean float64
+	// The probability of each number of steps, from best to worst, or of that many or fewer
+	pmf, cdf []float64
+}
+
+// chainStepsKey identifies a distribution of chain steps, which depends only on these values.
+type chainStepsKey struct {
+	targetSecurityLevel int
+	lgW                 int
+	d                   int
+}
+
+var (
+	// Guards chainStepsCache, which is read on every call from the search workers but rarely written
+	chainStepsMu sync.RWMutex
+	// The distributions computed so far, of which there are few since the key has few values
+	chainStepsCache = make(map[chainStepsKey]*chainSteps)
+)
+
+// verifyChainSteps returns the distribution of chain steps to verify a signature of the parameter
+// set, computing it if it has not been cached.
+func (p ParameterSet) verifyChainSteps() *chainSteps {
+	key := chainStepsKey{p.TargetSecurityLevel, p.LgW, p.D}
+	chainStepsMu.RLock()
+	steps, ok := chainStepsCache[key]
+	chainStepsMu.RUnlock()
+	if ok {
+		return steps
+	}
+
+	// Compute outside of the lock; concurrent callers may duplicate the work, but will agree on the
+	// result.
+	layer := p.layerChainSteps()
+	steps = layer
+	if p.D > 1 {
+		steps = newChainSteps(int64(p.D)*layer.best, convolvePower(layer.pmf, p.D))
+		// The mean is exactly that of the layers, without the rounding of the convolution
+		steps.mean = float64(p.D) * layer.mean
+	}
+
+	chainStepsMu.Lock()
+	chainStepsCache[key] = steps
+	chainStepsMu.Unlock()
+	return steps
+}
+
+// layerChainSteps returns the distribution of chain steps to verify a single WOTS+ signature.
+func (p ParameterSet) layerChainSteps() *chainSteps {
+	w := 1 << p.LgW
+	messageDigits := ceil(p.TargetSecurityLevel, p.LgW)
+	checksumDigits := p.WinternitzDigits() - messageDigits
+
+	// The verifier completes each chain from the digit to w-1, so the steps for the message digits
+	// are the checksum. Its distribution is that of the sum of messageDigits uniform digits.
+	maxChecksum := messageDigits * (w - 1)
+	checksums := []float64{1}
+	for range messageDigits {
+		next := make([]float64, len(checksums)+w-1)
+		// next[s] is the average of checksums[s-w+1] to checksums[s], kept as a running sum
+		var window float64
+		for s := range next {
+			if s < len(checksums) {
+				window += checksums[s]
+			}
+			if s >= w {
+				window -= checksums[s-w]
+			}
+			next[s] = window / float64(w)
+		}
+		checksums = next
+	}
+
+	// Add the steps for the checksum digits
+	pmf := make([]float64, maxChecksum+checksumDigits*(w-1)+1)
+	for checksum, probability := range checksums {
+		steps := checksum
+		for i, remaining := 0, checksum; i < checksumDigits; i, remaining = i+1, remaining/w {
+			steps += w - 1 - remaining%w
+		}
+		pmf[steps] += probability
+	}
+	// Trim the numbers of steps that cannot happen
+	first := slices.IndexFunc(pmf, func(probability float64) bool { return probability > 0 })
+	last := len(pmf) - 1
+	for pmf[last] == 0 {
+		last--
+	}
+	return newChainSteps(int64(first), pmf[first:last+1])
+}
+
+// newChainSteps returns the distribution with the given probability of each number of steps from
+// best on.
+func newChainSteps(best int64, pmf []float64) *chainSteps {
+	steps := &chainSteps{
+		best:  best,
+		worst: best + int64(len(pmf)) - 1,
+		pmf:   pmf,
+		cdf:   make([]float64, len(pmf)),
+	}
+	var total float64
+	for i, probability := range pmf {
+		total += probability
+		steps.cdf[i] = total
+		steps.mean += float64(best+int64(i)) * probability
+	}
+	return steps
+}
+
+// percentile returns the fewest steps that at least the given percentage of messages take no more
+// than.
+func (s *chainSteps) percentile(percentile float64) int64 {
+	if percentile >= 100 {
+		return s.worst
+	}
+	i := sort.SearchFloat64s(s.cdf, percentile/100)
+	return min(s.best+int64(i), s.worst)
+}
+
+// convolvePower returns the distribution of the sum of the given number of independent values with
+// the given distribution, by raising its discrete Fourier transform to that power.
+func convolvePower(pmf []float64, power int) []float64 {
+	size := 1 << bits.Len(uint(power*(len(pmf)-1)))
+	transform := make([]complex128, size)
+	for i, probability := range pmf {
+		transform[i] = complex(probability, 0)
+	}
+	fft(transform, false)
+	for i := range transform {
+		transform[i] = cmplx.Pow(transform[i], complex(float64(power), 0))
+	}
+	fft(transform, true)
+	result := make([]float64, power*(len(pmf)-1)+1)
+	for i := range result {
+		// Rounding leaves tiny (and possibly negative) values where there should be none
+		if probability := real(transform[i]); probability > 1e-15 {
+			result[i] = probability
+		}
+	}
+	return result
+}
+
+// fft computes the discrete Fourier transform (or its inverse) of the values in place. The number of
+// values must be a power of 2.
+func fft(values []complex128, inverse bool) {
+	n := len(values)
+	// Put the values in bit-reversed order
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+	for length := 2; length <= n; length <<= 1 {
+		angle := 2 * math.Pi / float64(length)
+		if inverse {
+			angle = -angle
+		}
+		root := cmplx.Rect(1, angle)
+		for start := 0; start < n; start += length {
+			factor := complex(1, 0)
+			for i := range length / 2 {
+				even, odd := values[start+i], values[start+i+length/2]*factor
+				values[start+i], values[start+i+length/2] = even+odd, even-odd
+				factor *= root
+			}
+		}
+	}
+	if inverse {
+		for i := range values {
+			values[i] /= complex(float64(n), 0)
+		}
+	}
+}
+
+// The calls to each hash function required to verify a signature, for the given percentile (from 0 to
+// 100) of messages: at least that percentage of messages take no more calls. 0 is the best case and
+// 100 the worst case.
+func (p ParameterSet) VerifyHashCallsAt(percentile float64) HashCalls {
+	calls := p.VerifyHashCalls()
+	calls.F = int64(p.K) + p.verifyChainSteps().percentile(percentile)
+	return calls
+}
+
+// The number of hash operations required to verify a signature in the worst case
+func (p ParameterSet) WorstVerifyHashes() int64 {
+	return p.VerifyHashCallsAt(100).Total()
+}
+
+// The number of hash operations required to verify a signature in the best case
+func (p ParameterSet) BestVerifyHashes() int64 {
+	return p.VerifyHashCallsAt(0).Total()
+}
+
+// The exact average number of hash operations required to verify a signature (VerifyHashes
+// approximates the steps of each WOTS+ chain as w/2)
+func (p ParameterSet) MeanVerifyHashes() float64 {
+	steps := p.verifyChainSteps()
+	return float64(p.VerifyHashCallsAt(0).Total()-steps.best) + steps.mean
+}
+
+// The cost of verifying a signature for the given percentile of messages (see VerifyHashCallsAt), in
+// the units of the parameter set's hash family
+func (p ParameterSet) VerifyCostAt(percentile float64) int64 {
+	return p.Cost(p.VerifyHashCallsAt(percentile))
+}